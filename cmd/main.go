@@ -5,10 +5,12 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -18,10 +20,87 @@ import (
 
 	"git.quad4.io/discourse-tui-client/internal/config"
 	"git.quad4.io/discourse-tui-client/internal/tui"
+	"git.quad4.io/discourse-tui-client/pkg/browsercookies"
 	"git.quad4.io/discourse-tui-client/pkg/discourse"
 	"git.quad4.io/discourse-tui-client/pkg/output"
 )
 
+// importBrowserCookies copies the instance's session cookies out of a local
+// browser into the cookies file, so the usual cookie loading picks them up.
+func importBrowserCookies(browser, instanceURL, cookiesPath string, encrypt bool) error {
+	client, err := discourse.NewClient(instanceURL, cookiesPath, encrypt)
+	if err != nil {
+		return err
+	}
+	parsedURL, err := url.Parse(client.BaseURL())
+	if err != nil {
+		return fmt.Errorf("invalid instance URL: %w", err)
+	}
+	cookies, err := browsercookies.Import(browser, parsedURL.Hostname())
+	if err != nil {
+		return err
+	}
+	if err := client.SetCookies(cookies); err != nil {
+		return err
+	}
+	if err := client.SaveCookies(cookiesPath); err != nil {
+		return fmt.Errorf("failed to save cookies: %w", err)
+	}
+	log.Printf("Imported %d cookies from %s into %s", len(cookies), browser, cookiesPath)
+	return config.SaveInstance(client.BaseURL())
+}
+
+// runLogin shows the login form and returns the instance that was logged in
+// to. It exits if no cookies were saved. expired is the error a saved session
+// was rejected with, if any; its cookies are removed first.
+func runLogin(cookiesPath string, encrypt bool, expired error) string {
+	if expired != nil {
+		log.Printf("Saved session was rejected (%v). Removing %s and logging in again.", expired, cookiesPath)
+		fmt.Println("Your saved session has expired. Please log in again.")
+		if err := os.Remove(cookiesPath); err != nil {
+			log.Printf("Failed to remove stale cookies: %v", err)
+		}
+	}
+	loginModel := tui.InitialLoginModel(nil, cookiesPath, encrypt) // Pass nil client initially, it will be created after login
+	p := tea.NewProgram(loginModel)
+	finalModel, runErr := p.Run()
+	if runErr != nil {
+		log.Printf("Login program error: %v", runErr)
+		fmt.Printf("Login error: %v\n", runErr)
+		os.Exit(1)
+	}
+	if _, statErrAfterLogin := os.Stat(cookiesPath); os.IsNotExist(statErrAfterLogin) {
+		log.Printf("Login failed or was quit, cookies file not created at %s.", cookiesPath)
+		fmt.Println("Login failed or was quit, cookies file not created.")
+		os.Exit(1)
+	}
+	log.Printf("Cookies file successfully created/found at %s after login.", cookiesPath)
+
+	// The form may have grown a two-factor field, so read the final model
+	if final, ok := finalModel.(interface{ GetInstanceURL() string }); ok {
+		return final.GetInstanceURL()
+	}
+	return loginModel.GetInstanceURL() // Update instanceURL from login model
+}
+
+// runSetup runs the first-run wizard. It returns the chosen instance and
+// whether to browse without logging in, and exits if the wizard is quit.
+func runSetup(cookiesPath string, encrypt bool, settingsPath string) (string, bool) {
+	setupModel := tui.InitialSetupModel(cookiesPath, encrypt, settingsPath)
+	p := tea.NewProgram(setupModel)
+	if _, err := p.Run(); err != nil {
+		log.Printf("Setup program error: %v", err)
+		fmt.Printf("Setup error: %v\n", err)
+		os.Exit(1)
+	}
+	if !setupModel.Completed {
+		log.Printf("Setup was quit before finishing.")
+		fmt.Println("Setup was quit before finishing; run again to start over or use --no-setup.")
+		os.Exit(1)
+	}
+	return setupModel.InstanceURL(), setupModel.NoAuth
+}
+
 func setupLogging() (*os.File, error) {
 	userCacheDir, err := os.UserCacheDir()
 	if err != nil {
@@ -52,38 +131,128 @@ func main() {
 	flag.StringVar(instanceURL, "u", "", "Discourse instance URL (shorthand).")
 	logout := flag.Bool("logout", false, "Logout and delete cookies.")
 	flag.BoolVar(logout, "l", false, "Logout and delete cookies (shorthand).")
-	resetCache := flag.Bool("reset-cache", false, "Reset cache and force fresh fetch.")
+	resetCache := flag.Bool("reset-cache", false, "Reset cache and force fresh fetch (only the --url instance's cache if given).")
 	flag.BoolVar(resetCache, "r", false, "Reset cache and force fresh fetch (shorthand).")
-	outputPath := flag.String("output", "", "Output posts to file (txt, json, or html)")
+	resetCacheAll := flag.Bool("reset-cache-all", false, "Reset the cache of every instance.")
+	outputPath := flag.String("output", "", "Output posts to file (txt, json, html, md, csv, or atom)")
 	flag.StringVar(outputPath, "o", "", "Output posts to file (shorthand)")
+	outputTopic := flag.Int("output-topic", 0, "Write only the topic with this ID to the --output file")
+	concurrency := flag.Int("concurrency", output.DefaultConcurrency, "Number of topics whose posts are fetched at once for --output")
 	cooldown := flag.Duration("cooldown", 500*time.Millisecond, "Cooldown between page fetches (e.g. 500ms)")
 	loadAll := flag.Bool("load-all", false, "Load all available topics at startup (may be slow)")
 	flag.BoolVar(loadAll, "a", false, "Load all available topics at startup (shorthand)")
+	timeout := flag.Duration("timeout", discourse.DefaultTimeout, "Give up on a request after this long, including reading the response (0 for no limit)")
+	loadAllTimeout := flag.Duration("load-all-timeout", 30*time.Second, "Stop loading all topics after this long (0 for no limit)")
 	noAuth := flag.Bool("no-auth", false, "Run in unauthenticated mode.")
 	flag.BoolVar(noAuth, "na", false, "Run in unauthenticated mode (shorthand).")
 	encryptCookies := flag.Bool("encrypt-cookies", false, "Encrypt cookies file with a password.")
 	flag.BoolVar(encryptCookies, "e", false, "Encrypt cookies file with a password (shorthand).")
+	minTLS := flag.String("min-tls", "1.2", "Minimum TLS version to accept (1.0, 1.1, 1.2 or 1.3)")
+	idleConns := flag.Int("idle-conns", discourse.DefaultMaxIdleConnsPerHost, "Number of idle connections to keep open to the forum")
+	idleTimeout := flag.Duration("idle-timeout", discourse.DefaultIdleConnTimeout, "How long to keep an idle connection open (0 for no limit)")
+	maxRetries := flag.Int("max-retries", discourse.DefaultMaxRetries, "How many times to retry a request the forum rate limited (0 to fail right away)")
+	postBatchSize := flag.Int("post-batch-size", discourse.DefaultPostBatchSize, "Number of posts to request at once when opening a topic")
+	importCookiesFrom := flag.String("import-cookies-from-browser", "", "Import session cookies for the instance from a browser (firefox)")
+	account := flag.String("account", "", "Use the saved session of this account on the instance")
+	addAccount := flag.Bool("add-account", false, "Log in to another account on the instance and make it the default")
+	keepStaleCookies := flag.Bool("keep-stale-cookies", false, "Keep using saved cookies even if the forum no longer accepts them")
+	prefetch := flag.Int("prefetch", 0, "Prefetch posts of the first N topics in the background")
+	noSetup := flag.Bool("no-setup", false, "Skip the first-run setup wizard and show the plain login screen")
+	continueReading := flag.Bool("continue", false, "Open the first topic with unread posts at its first unread post")
+	chat := flag.Bool("chat", false, "Enable read-only access to the forum's chat channels (needs the chat plugin)")
+	apiKey := flag.String("api-key", "", "Authenticate with this API key instead of logging in (needs --api-username)")
+	apiUsername := flag.String("api-username", "", "User the --api-key acts as")
+	profile := flag.String("profile", "", "Use the forum saved under this profile name")
+	refreshInterval := flag.Duration("refresh-interval", 5*time.Minute, "How often the topic list refreshes itself; 0 turns it off")
+	theme := flag.String("theme", "", "Color theme to start from (red, blue, green, dark, light, solarized or mono); colors.txt still overrides single colors")
 	flag.Parse()
 
+	cooldownSet := false
+	refreshIntervalSet := false
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "cooldown":
+			cooldownSet = true
+		case "refresh-interval":
+			refreshIntervalSet = true
+		}
+	})
+
 	if *outputPath != "" {
-		if !strings.HasSuffix(*outputPath, ".txt") && !strings.HasSuffix(*outputPath, ".json") && !strings.HasSuffix(*outputPath, ".html") {
-			fmt.Println("Output file must end with .txt, .json, or .html")
+		if !strings.HasSuffix(*outputPath, ".txt") && !strings.HasSuffix(*outputPath, ".json") && !strings.HasSuffix(*outputPath, ".html") && !strings.HasSuffix(*outputPath, ".md") && !strings.HasSuffix(*outputPath, ".csv") && !strings.HasSuffix(*outputPath, ".atom") {
+			fmt.Println("Output file must end with .txt, .json, .html, .md, .csv, or .atom")
 			os.Exit(1)
 		}
 	}
+	if *outputTopic != 0 && (*outputPath == "" || *outputTopic < 0) {
+		fmt.Println("--output-topic needs a topic ID and an --output file")
+		os.Exit(1)
+	}
+
+	if *timeout < 0 {
+		fmt.Println("--timeout must not be negative")
+		os.Exit(1)
+	}
+
+	if (*apiKey == "") != (*apiUsername == "") {
+		fmt.Println("--api-key and --api-username must be given together")
+		os.Exit(1)
+	}
 
-	if *resetCache {
+	if *theme != "" {
+		if _, ok := config.ThemeByName(*theme); !ok {
+			fmt.Printf("Unknown theme %q. Available themes: %s\n", *theme, strings.Join(config.ThemeNames(), ", "))
+			os.Exit(1)
+		}
+	}
+
+	if *profile != "" {
+		p, err := config.LoadProfile(*profile)
+		if err != nil {
+			profiles, _ := config.ListProfiles()
+			names := make([]string, len(profiles))
+			for i, p := range profiles {
+				names[i] = p.Name
+			}
+			fmt.Printf("%v. Saved profiles: %s\n", err, strings.Join(names, ", "))
+			os.Exit(1)
+		}
+		if *instanceURL == "" {
+			*instanceURL = p.URL
+		}
+		// With a single saved account on the forum, use its session rather
+		// than cookies.txt, which belongs to whichever forum was used last
+		if *account == "" && !*addAccount {
+			if accounts, _ := config.ListAccounts(*instanceURL); len(accounts) == 1 {
+				*account = accounts[0]
+			}
+		}
+	}
+
+	minTLSVersion, err := discourse.ParseTLSVersion(*minTLS)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *resetCache || *resetCacheAll {
 		userCacheDir, err := os.UserCacheDir()
 		if err != nil {
 			fmt.Printf("Failed to get user cache directory: %v\n", err)
 			os.Exit(1)
 		}
 		cacheDir := filepath.Join(userCacheDir, "discourse-tui-client", "instances")
+		if *instanceURL != "" && !*resetCacheAll {
+			if cacheDir, err = discourse.InstanceCacheDir(*instanceURL); err != nil {
+				fmt.Printf("Failed to get user cache directory: %v\n", err)
+				os.Exit(1)
+			}
+		}
 		if err := os.RemoveAll(cacheDir); err != nil {
 			fmt.Printf("Failed to reset cache: %v\n", err)
 			os.Exit(1)
 		}
-		fmt.Println("Cache reset successfully.")
+		fmt.Printf("Cache reset successfully: cleared %s\n", cacheDir)
 		os.Exit(0)
 	}
 
@@ -105,7 +274,6 @@ func main() {
 	}
 
 	var logFile *os.File
-	var err error
 
 	if *debug {
 		logFile, err = setupLogging()
@@ -147,6 +315,7 @@ func main() {
 	}
 
 	colorsPath := filepath.Join(appConfigDir, "colors.txt")
+	settingsPath := filepath.Join(appConfigDir, "settings.txt")
 
 	instanceName := "placeholder"
 	if *instanceURL != "" {
@@ -156,13 +325,55 @@ func main() {
 
 	log.Printf("Using cookies path: %s", defaultCookiesPath)
 	log.Printf("Using colors path: %s", colorsPath)
+	log.Printf("Using settings path: %s", settingsPath)
 	log.Printf("Using latest topics cache path: %s", latestTopicsCachePath)
 
-	loadedColors, err := config.LoadColors(colorsPath)
+	_, settingsErr := os.Stat(settingsPath)
+	settings, err := config.LoadSettings(settingsPath)
 	if err != nil {
-		log.Printf("Failed to load colors from %s: %v. Using default colors.", colorsPath, err)
+		log.Printf("Failed to load settings from %s: %v. Using defaults.", settingsPath, err)
+	}
+	config.Current = settings
+
+	// colors.txt is layered on the theme from settings, and --theme wins over both
+	loadColors := func() config.ColorConfig {
+		themeColors, ok := config.ThemeByName(config.Current.Theme)
+		if !ok {
+			themeColors = config.DefaultColors
+		}
+		loadedColors, err := config.LoadThemeColors(colorsPath, themeColors)
+		if err != nil {
+			log.Printf("Failed to load colors from %s: %v. Using default colors.", colorsPath, err)
+		}
+		if *theme != "" {
+			loadedColors, _ = config.ThemeByName(*theme)
+		}
+		config.UpdateStyles(loadedColors)
+		return loadedColors
+	}
+	loadedColors := loadColors()
+
+	_, cookiesErr := os.Stat(defaultCookiesPath)
+	firstRun := os.IsNotExist(settingsErr) && os.IsNotExist(cookiesErr)
+	if firstRun && !*noSetup && !*noAuth && *apiKey == "" && *instanceURL == "" && *account == "" && !*addAccount && *importCookiesFrom == "" {
+		log.Printf("No settings or cookies found. Starting the setup wizard.")
+		*instanceURL, *noAuth = runSetup(defaultCookiesPath, *encryptCookies, settingsPath)
+		loadedColors = loadColors()
+	}
+	// How the wizard chose to access the forum sticks unless flags pick another way
+	if !*noAuth && *apiKey == "" && !*addAccount && *account == "" && *importCookiesFrom == "" {
+		if config.Current.APIKey != "" && config.Current.APIUsername != "" {
+			*apiKey, *apiUsername = config.Current.APIKey, config.Current.APIUsername
+		} else if config.Current.NoAuth {
+			*noAuth = true
+		}
+	}
+	if !cooldownSet {
+		*cooldown = config.Current.Cooldown
+	}
+	if !refreshIntervalSet {
+		*refreshInterval = config.Current.RefreshInterval
 	}
-	config.UpdateStyles(loadedColors)
 
 	var client *discourse.Client
 	var clientCookiesPath string
@@ -170,30 +381,58 @@ func main() {
 	if *noAuth {
 		log.Println("Running in unauthenticated mode. Skipping login.")
 		clientCookiesPath = "" // No cookies path needed for unauthenticated
+		if *instanceURL == "" {
+			*instanceURL, _ = config.LoadInstance()
+		}
 		// If no instance URL is provided in unauthenticated mode, use a default one
 		if *instanceURL == "" {
 			*instanceURL = "https://meta.discourse.org" // A common public Discourse instance
 			log.Printf("No instance URL provided in unauthenticated mode, using default: %s", *instanceURL)
 		}
+	} else if *apiKey != "" {
+		log.Println("Authenticating with an API key. Skipping login.")
+		clientCookiesPath = ""
+		if *instanceURL == "" {
+			*instanceURL, _ = config.LoadInstance()
+		}
+		if *instanceURL == "" {
+			fmt.Println("--api-key needs --url to know which forum it is for")
+			os.Exit(1)
+		}
 	} else {
 		clientCookiesPath = defaultCookiesPath
-		if _, statErr := os.Stat(defaultCookiesPath); os.IsNotExist(statErr) {
-			log.Printf("Cookies file not found at %s. Initiating login.", defaultCookiesPath)
-			loginModel := tui.InitialLoginModel(nil, defaultCookiesPath, *encryptCookies) // Pass nil client initially, it will be created after login
-			p := tea.NewProgram(loginModel)
-			if _, runErr := p.Run(); runErr != nil {
-				log.Printf("Login program error: %v", runErr)
-				fmt.Printf("Login error: %v\n", runErr)
+		if *importCookiesFrom != "" {
+			if *instanceURL == "" {
+				*instanceURL, _ = config.LoadInstance()
+			}
+			if *instanceURL == "" {
+				fmt.Println("--import-cookies-from-browser needs --url to know which forum's cookies to import")
 				os.Exit(1)
 			}
-			if _, statErrAfterLogin := os.Stat(defaultCookiesPath); os.IsNotExist(statErrAfterLogin) {
-				log.Printf("Login failed or was quit, cookies file not created at %s.", defaultCookiesPath)
-				fmt.Println("Login failed or was quit, cookies file not created.")
+			if err := importBrowserCookies(*importCookiesFrom, *instanceURL, defaultCookiesPath, *encryptCookies); err != nil {
+				log.Printf("Failed to import cookies from %s: %v", *importCookiesFrom, err)
+				fmt.Printf("Failed to import cookies from %s: %v\n", *importCookiesFrom, err)
 				os.Exit(1)
 			}
-			log.Printf("Cookies file successfully created/found at %s after login.", defaultCookiesPath)
-
-			*instanceURL = loginModel.GetInstanceURL() // Update instanceURL from login model
+		}
+		if *addAccount {
+			log.Printf("Logging in to an additional account.")
+			*instanceURL = runLogin(defaultCookiesPath, *encryptCookies, nil)
+		}
+		if *account != "" {
+			if *instanceURL == "" {
+				*instanceURL, _ = config.LoadInstance()
+			}
+			accountPath := config.AccountCookiesPath(*instanceURL, *account)
+			if _, err := os.Stat(accountPath); err != nil {
+				accounts, _ := config.ListAccounts(*instanceURL)
+				fmt.Printf("No saved session for account %q on %s. Saved accounts: %s\n", *account, *instanceURL, strings.Join(accounts, ", "))
+				os.Exit(1)
+			}
+			clientCookiesPath = accountPath
+		} else if _, statErr := os.Stat(defaultCookiesPath); os.IsNotExist(statErr) {
+			log.Printf("Cookies file not found at %s. Initiating login.", defaultCookiesPath)
+			*instanceURL = runLogin(defaultCookiesPath, *encryptCookies, nil)
 		}
 	}
 
@@ -212,22 +451,82 @@ func main() {
 		*instanceURL = "https://placeholder.com" // Fallback if no URL is provided and not in no-auth mode
 	}
 
-	client, err = discourse.NewClient(*instanceURL, clientCookiesPath, *encryptCookies)
-	if err != nil {
-		log.Printf("Failed to create client: %v", err)
-		fmt.Printf("Failed to create client: %v\n", err)
-		os.Exit(1)
-	}
-	client.SetPageCooldown(*cooldown)
+	setupClient := func() *discourse.Client {
+		client, err := discourse.NewClient(*instanceURL, clientCookiesPath, *encryptCookies)
+		if err != nil {
+			log.Printf("Failed to create client: %v", err)
+			fmt.Printf("Failed to create client: %s\n", discourse.ErrorMessage(err))
+			os.Exit(1)
+		}
+		client.SetPageCooldown(*cooldown)
+		client.SetTimeout(*timeout)
+		client.SetLoadAllTimeout(*loadAllTimeout)
+		if err := client.SetPostBatchSize(*postBatchSize); err != nil {
+			log.Printf("Invalid --post-batch-size: %v", err)
+			fmt.Printf("Invalid --post-batch-size: %v\n", err)
+			os.Exit(1)
+		}
+		if err := client.SetMaxRetries(*maxRetries); err != nil {
+			log.Printf("Invalid --max-retries: %v", err)
+			fmt.Printf("Invalid --max-retries: %v\n", err)
+			os.Exit(1)
+		}
+		client.SetPathPrefix(config.Current.PathPrefix)
+		client.SetAcceptLanguage(config.Current.AcceptLanguage)
+		if err := client.SetMinTLSVersion(minTLSVersion); err != nil {
+			log.Printf("Failed to set minimum TLS version: %v", err)
+		}
+		if err := client.SetIdleConns(max(discourse.DefaultMaxIdleConns, *idleConns), *idleConns, *idleTimeout); err != nil {
+			log.Printf("Invalid --idle-conns or --idle-timeout: %v", err)
+			fmt.Printf("Invalid --idle-conns or --idle-timeout: %v\n", err)
+			os.Exit(1)
+		}
 
-	// Load cookies if not in no-auth mode
-	if !*noAuth {
-		if err := client.LoadCookies(clientCookiesPath); err != nil {
-			log.Printf("Failed to load cookies from %s: %v", clientCookiesPath, err)
-			fmt.Printf("Failed to load cookies from %s: %v\n", clientCookiesPath, err)
+		if *apiKey != "" {
+			client.SetAPIKey(*apiKey, *apiUsername)
+		}
+
+		// Load cookies if not in no-auth mode
+		if !*noAuth && *apiKey == "" {
+			if err := client.LoadCookies(clientCookiesPath); err != nil {
+				log.Printf("Failed to load cookies from %s: %v", clientCookiesPath, err)
+				fmt.Printf("Failed to load cookies from %s: %v\n", clientCookiesPath, err)
+				os.Exit(1)
+			}
+			log.Printf("Successfully loaded cookies from %s", clientCookiesPath)
+		}
+		return client
+	}
+	client = setupClient()
+
+	// Cookies that exist but are no longer accepted would otherwise leave us
+	// with an empty, anonymous topic list. The user found is handed to the
+	// TUI so it needn't ask again.
+	var currentUser *discourse.UserProfile
+	if *apiKey != "" {
+		user, err := client.GetCurrentUser()
+		if err != nil {
+			log.Printf("API key check failed: %v", err)
+			fmt.Printf("The forum did not accept the API key: %s\n", discourse.ErrorMessage(err))
 			os.Exit(1)
 		}
-		log.Printf("Successfully loaded cookies from %s", clientCookiesPath)
+		currentUser = user
+	} else if !*noAuth && !*keepStaleCookies {
+		user, err := client.GetCurrentUser()
+		if err == nil {
+			currentUser = user
+			// Sessions from before accounts were tracked only live in cookies.txt
+			accountPath := config.AccountCookiesPath(client.BaseURL(), user.Username)
+			if _, statErr := os.Stat(accountPath); os.IsNotExist(statErr) {
+				if err := client.SaveCookies(accountPath); err != nil {
+					log.Printf("Failed to save cookies for account %s: %v", user.Username, err)
+				}
+			}
+		}
+		if errors.Is(err, discourse.ErrSessionExpired) || errors.Is(err, discourse.ErrLoginRequired) {
+			*instanceURL = runLogin(clientCookiesPath, *encryptCookies, err)
+			client = setupClient()
+		}
 	}
 
 	instanceName = strings.TrimPrefix(strings.TrimPrefix(*instanceURL, "https://"), "http://")
@@ -244,6 +543,18 @@ func main() {
 		}
 	}
 
+	// A single topic doesn't need the topic list
+	if *outputTopic != 0 {
+		output.SetClient(client)
+		if err := output.WriteTopicToFile(*outputPath, *outputTopic); err != nil {
+			log.Printf("Failed to write output file: %v", err)
+			fmt.Printf("Failed to write output file: %s\n", discourse.ErrorMessage(err))
+			os.Exit(1)
+		}
+		fmt.Printf("Successfully wrote topic %d to %s\n", *outputTopic, *outputPath)
+		os.Exit(0)
+	}
+
 	var topicsResponse *discourse.Response
 
 	/* #nosec G304 */
@@ -274,45 +585,39 @@ func main() {
 		var networkResponse *discourse.Response
 		var fetchErr error
 
-		if *loadAll {
-			log.Println("Loading all available topics (this may take a while)...")
-			networkResponse, fetchErr = client.LoadAllTopics(20)
-		} else {
-			networkResponse, fetchErr = client.GetLatestTopics()
+		fetchTopics := func() {
+			if *loadAll {
+				log.Println("Loading all available topics (this may take a while)...")
+				networkResponse, fetchErr = client.LoadAllTopics(20)
+			} else {
+				networkResponse, fetchErr = client.GetLatestTopics()
+			}
 		}
+		fetchTopics()
 
-		if fetchErr != nil {
-			log.Printf("Failed to fetch topics: %v", fetchErr)
-			fmt.Printf("Failed to fetch topics: %v\n", fetchErr)
-			os.Exit(1)
+		// With --keep-stale-cookies the session isn't checked up front
+		if errors.Is(fetchErr, discourse.ErrSessionExpired) && !*noAuth && *apiKey == "" {
+			*instanceURL = runLogin(clientCookiesPath, *encryptCookies, fetchErr)
+			client = setupClient()
+			fetchTopics()
 		}
-		topicsResponse = networkResponse
 
-		categories, err := client.GetCategories()
-		if err != nil {
-			log.Printf("Warning: failed to fetch categories: %v", err)
-		} else {
-			categoryMap := make(map[int]struct {
-				Name  string
-				Color string
-			})
-			for _, category := range categories.CategoryList.Categories {
-				categoryMap[category.ID] = struct {
-					Name  string
-					Color string
-				}{
-					Name:  category.Name,
-					Color: category.Color,
-				}
-			}
-
-			for i := range topicsResponse.TopicList.Topics {
-				if cat, ok := categoryMap[topicsResponse.TopicList.Topics[i].CategoryID]; ok {
-					topicsResponse.TopicList.Topics[i].CategoryName = cat.Name
-					topicsResponse.TopicList.Topics[i].CategoryColor = cat.Color
+		if fetchErr != nil {
+			log.Printf("Failed to fetch topics: %v", fetchErr)
+			// Tell an unreachable or mistyped forum apart from a failing one
+			if pingErr := client.Ping(); pingErr != nil {
+				log.Printf("Ping failed: %v", pingErr)
+				if discourse.IsNetworkError(pingErr) {
+					fmt.Printf("Could not reach Discourse at %s: %s\n", client.BaseURL(), discourse.ErrorMessage(pingErr))
+				} else {
+					fmt.Println(discourse.ErrorMessage(pingErr))
 				}
+				os.Exit(1)
 			}
+			fmt.Printf("Failed to fetch topics: %s\n", discourse.ErrorMessage(fetchErr))
+			os.Exit(1)
 		}
+		topicsResponse = networkResponse
 
 		jsonData, marshalErr := json.MarshalIndent(topicsResponse, "", "  ")
 		if marshalErr == nil {
@@ -334,9 +639,13 @@ func main() {
 
 	if *outputPath != "" {
 		output.SetClient(client)
+		if err := output.SetConcurrency(*concurrency); err != nil {
+			fmt.Printf("Invalid --concurrency: %v\n", err)
+			os.Exit(1)
+		}
 		if err := output.WriteToFile(*outputPath, topicsResponse); err != nil {
 			log.Printf("Failed to write output file: %v", err)
-			fmt.Printf("Failed to write output file: %v\n", err)
+			fmt.Printf("Failed to write output file: %s\n", discourse.ErrorMessage(err))
 			os.Exit(1)
 		}
 		fmt.Printf("Successfully wrote output to %s\n", *outputPath)
@@ -347,6 +656,26 @@ func main() {
 
 	initialModel := tui.InitialModel(client, topicsResponse.TopicList.Topics)
 	initialModel.MoreTopicsURL = topicsResponse.TopicList.MoreTopicsURL
+	initialModel.CanCreateTopic = topicsResponse.TopicList.CanCreateTopic
+	initialModel.CurrentUser = currentUser
+	initialModel.Debug = *debug
+	initialModel.PrefetchCount = *prefetch
+	initialModel.RefreshInterval = *refreshInterval
+	initialModel.Colors = loadedColors
+	initialModel.ColorsPath = colorsPath
+	initialModel.SettingsPath = settingsPath
+	initialModel.ChatEnabled = *chat
+	if *continueReading && !initialModel.ContinueReading() {
+		log.Println("No unread topics to continue with")
+	}
+
+	lastRunPath := filepath.Join(appCacheDir, "instances", instanceName, "last_run")
+	runStarted := time.Now()
+	if lastRun, err := config.LoadLastRun(lastRunPath); err != nil {
+		log.Printf("Failed to load last run time: %v", err)
+	} else {
+		initialModel.LastVisit = lastRun
+	}
 
 	p := tea.NewProgram(
 		initialModel,
@@ -359,5 +688,8 @@ func main() {
 		fmt.Printf("Error running TUI: %v\n", runErr)
 		os.Exit(1)
 	}
+	if err := config.SaveLastRun(lastRunPath, runStarted); err != nil {
+		log.Printf("Failed to save last run time: %v", err)
+	}
 	log.Println("Discourse client exited normally.")
 }
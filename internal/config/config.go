@@ -4,10 +4,13 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
 )
@@ -28,18 +31,57 @@ var DefaultColors = ColorConfig{
 	Error:    "#FF0000",
 }
 
+// ThemePreset is a named set of colors offered by the setup wizard and
+// chosen with --theme by its Key.
+type ThemePreset struct {
+	Key    string
+	Name   string
+	Colors ColorConfig
+}
+
+var ThemePresets = []ThemePreset{
+	{Key: "red", Name: "Red (default)", Colors: DefaultColors},
+	{Key: "blue", Name: "Blue", Colors: ColorConfig{Title: "#5FAFFF", Item: "#87AFD7", Selected: "#0087FF", Status: "#5F87AF", Error: "#FF5F5F"}},
+	{Key: "green", Name: "Green", Colors: ColorConfig{Title: "#5FD75F", Item: "#87D787", Selected: "#00AF00", Status: "#5F875F", Error: "#FF5F5F"}},
+	{Key: "dark", Name: "Dark", Colors: ColorConfig{Title: "#E4E4E4", Item: "#A8A8A8", Selected: "#FFFFFF", Status: "#808080", Error: "#FF5F5F"}},
+	{Key: "light", Name: "Light", Colors: ColorConfig{Title: "#1C1C1C", Item: "#444444", Selected: "#005FAF", Status: "#6C6C6C", Error: "#D70000"}},
+	{Key: "solarized", Name: "Solarized", Colors: ColorConfig{Title: "#268BD2", Item: "#839496", Selected: "#B58900", Status: "#586E75", Error: "#DC322F"}},
+	{Key: "mono", Name: "Monochrome", Colors: ColorConfig{Title: "15", Item: "250", Selected: "15", Status: "244", Error: "9"}},
+}
+
+// ThemeByName returns the colors of the preset with the given key.
+func ThemeByName(name string) (ColorConfig, bool) {
+	for _, preset := range ThemePresets {
+		if strings.EqualFold(preset.Key, name) {
+			return preset.Colors, true
+		}
+	}
+	return ColorConfig{}, false
+}
+
+// ThemeNames lists the keys accepted by ThemeByName.
+func ThemeNames() []string {
+	names := make([]string, len(ThemePresets))
+	for i, preset := range ThemePresets {
+		names[i] = preset.Key
+	}
+	return names
+}
+
 func LoadColors(path string) (ColorConfig, error) {
-	colors := DefaultColors
+	return LoadThemeColors(path, DefaultColors)
+}
+
+// LoadThemeColors starts from a theme and applies the colors set in the
+// file at path over it, so a file only overrides the keys it lists.
+func LoadThemeColors(path string, theme ColorConfig) (ColorConfig, error) {
+	colors := theme
 	/* #nosec G304 */
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
-			// Create default colors file
-			if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
-				return colors, fmt.Errorf("failed to create config directory: %w", err)
-			}
-			if err := os.WriteFile(path, []byte(fmt.Sprintf("title=%s\nitem=%s\nselected=%s\nstatus=%s\nerror=%s",
-				colors.Title, colors.Item, colors.Selected, colors.Status, colors.Error)), 0600); err != nil { //nosec G306
+			// Leave a commented-out template so the theme still applies
+			if err := saveColorsTemplate(path, colors); err != nil {
 				return colors, fmt.Errorf("failed to write default colors: %w", err)
 			}
 			return colors, nil
@@ -72,6 +114,207 @@ func LoadColors(path string) (ColorConfig, error) {
 	return colors, nil
 }
 
+// saveColorsTemplate writes colors to path commented out, as a starting
+// point for editing that doesn't override anything yet.
+func saveColorsTemplate(path string, colors ColorConfig) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(fmt.Sprintf("# Uncomment a line to override the theme's color\n# title=%s\n# item=%s\n# selected=%s\n# status=%s\n# error=%s\n",
+		colors.Title, colors.Item, colors.Selected, colors.Status, colors.Error)), 0600) //nosec G306
+}
+
+func SaveColors(path string, colors ColorConfig) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(fmt.Sprintf("title=%s\nitem=%s\nselected=%s\nstatus=%s\nerror=%s",
+		colors.Title, colors.Item, colors.Selected, colors.Status, colors.Error)), 0600) //nosec G306
+}
+
+// Settings holds behaviour options read from settings.txt.
+type Settings struct {
+	// UnknownCategory decides how topics in a category missing from the
+	// category list (e.g. a restricted subcategory) are labelled:
+	// "label" shows [uncategorized], "id" shows the raw ID, "hide" shows nothing.
+	UnknownCategory string
+	// PathPrefix is inserted before every API path, for instances served
+	// from a subfolder or a locale prefix such as "/en".
+	PathPrefix string
+	// AcceptLanguage, if set, is sent as the Accept-Language header.
+	AcceptLanguage string
+	// Layout is the preferred screen layout: LayoutSplit, LayoutList or LayoutViewport.
+	Layout string
+	// PostDivider is the line drawn between posts: "line", "double", "thick" or "ascii".
+	PostDivider string
+	// PinToTop keeps pinned topics at the top of the latest list; off gives
+	// strict bump order.
+	PinToTop bool
+	// HideWhispers leaves staff whispers out of topics.
+	HideWhispers bool
+	// LinkStyle decides how links in posts are rendered: LinkStyleBoth gives
+	// "text (url)", LinkStyleText only the text and LinkStyleURL only the URL.
+	// Only the TUI uses it; the output formatters keep their format's links.
+	LinkStyle string
+	// ShowSolved marks accepted answers and solved topics on instances
+	// running the discourse-solved plugin.
+	ShowSolved bool
+	// DeferRefresh holds the automatic topic list refresh back while a
+	// topic is open fullscreen or a form or overlay is up.
+	DeferRefresh bool
+	// StripTracking removes utm_ and similar tracking parameters from links.
+	StripTracking bool
+	// ShowThumbnails marks topics that have a featured image. Terminals
+	// can't draw the image itself yet, so it is only an indicator.
+	ShowThumbnails bool
+	// CategorySort orders the category picker: CategorySortPosition keeps
+	// the forum's own order, CategorySortPosts and CategorySortTopics put the
+	// busiest categories first.
+	CategorySort string
+	// Cooldown is the pause between page fetches when --cooldown isn't given.
+	Cooldown time.Duration
+	// ConfirmQuit asks before q quits. Leaving a composer with a draft always
+	// asks, and ctrl+c never does.
+	ConfirmQuit bool
+	// RefreshInterval is how often the topic list refreshes itself when
+	// --refresh-interval isn't given; 0 turns automatic refresh off.
+	RefreshInterval time.Duration
+	// Theme is the key of the preset colors.txt is layered on; empty or
+	// unknown means the default red theme.
+	Theme string
+	// NoAuth starts without logging in, as if --no-auth was given.
+	NoAuth bool
+	// APIKey and APIUsername authenticate with an API key when --api-key
+	// isn't given.
+	APIKey      string
+	APIUsername string
+}
+
+const (
+	LayoutSplit    = "split"
+	LayoutList     = "list"
+	LayoutViewport = "viewport"
+)
+
+const (
+	CategorySortPosition = "position"
+	CategorySortPosts    = "posts"
+	CategorySortTopics   = "topics"
+)
+
+const (
+	LinkStyleBoth = "both"
+	LinkStyleText = "text"
+	LinkStyleURL  = "url"
+)
+
+var DefaultSettings = Settings{
+	UnknownCategory: "label",
+	Layout:          LayoutSplit,
+	PostDivider:     "line",
+	PinToTop:        true,
+	LinkStyle:       LinkStyleBoth,
+	ShowSolved:      true,
+	DeferRefresh:    true,
+	ShowThumbnails:  true,
+	CategorySort:    CategorySortPosition,
+	Cooldown:        500 * time.Millisecond,
+	RefreshInterval: 5 * time.Minute,
+}
+
+// Current is the settings in effect; set once at startup like the styles below.
+var Current = DefaultSettings
+
+func LoadSettings(path string) (Settings, error) {
+	settings := DefaultSettings
+	/* #nosec G304 */
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return settings, nil
+		}
+		return settings, fmt.Errorf("failed to read settings file: %w", err)
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for _, line := range lines {
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(parts[0])
+		value := strings.TrimSpace(parts[1])
+
+		switch key {
+		case "unknown_category":
+			settings.UnknownCategory = value
+		case "path_prefix":
+			settings.PathPrefix = value
+		case "accept_language":
+			settings.AcceptLanguage = value
+		case "hide_whispers":
+			settings.HideWhispers = value == "true"
+		case "strip_tracking":
+			settings.StripTracking = value == "true"
+		case "confirm_quit":
+			settings.ConfirmQuit = value == "true"
+		case "defer_refresh":
+			settings.DeferRefresh = value != "false"
+		case "show_thumbnails":
+			settings.ShowThumbnails = value != "false"
+		case "show_solved":
+			settings.ShowSolved = value != "false"
+		case "pin_to_top":
+			settings.PinToTop = value != "false"
+		case "post_divider":
+			settings.PostDivider = value
+		case "link_style":
+			switch value {
+			case LinkStyleBoth, LinkStyleText, LinkStyleURL:
+				settings.LinkStyle = value
+			}
+		case "cooldown":
+			if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+				settings.Cooldown = d
+			}
+		case "refresh_interval":
+			if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+				settings.RefreshInterval = d
+			}
+		case "theme":
+			if _, ok := ThemeByName(value); ok {
+				settings.Theme = value
+			}
+		case "no_auth":
+			settings.NoAuth = value == "true"
+		case "api_key":
+			settings.APIKey = value
+		case "api_username":
+			settings.APIUsername = value
+		case "category_sort":
+			switch value {
+			case CategorySortPosition, CategorySortPosts, CategorySortTopics:
+				settings.CategorySort = value
+			}
+		case "layout":
+			switch value {
+			case LayoutSplit, LayoutList, LayoutViewport:
+				settings.Layout = value
+			}
+		}
+	}
+	return settings, nil
+}
+
+func SaveSettings(path string, settings Settings) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create settings directory: %w", err)
+	}
+	data := fmt.Sprintf("unknown_category=%s\npath_prefix=%s\naccept_language=%s\nlayout=%s\npost_divider=%s\npin_to_top=%t\nhide_whispers=%t\nlink_style=%s\nshow_solved=%t\ndefer_refresh=%t\nstrip_tracking=%t\nshow_thumbnails=%t\ncategory_sort=%s\ncooldown=%s\nconfirm_quit=%t\nrefresh_interval=%s\ntheme=%s\nno_auth=%t\napi_key=%s\napi_username=%s\n",
+		settings.UnknownCategory, settings.PathPrefix, settings.AcceptLanguage, settings.Layout, settings.PostDivider, settings.PinToTop, settings.HideWhispers, settings.LinkStyle, settings.ShowSolved, settings.DeferRefresh, settings.StripTracking, settings.ShowThumbnails, settings.CategorySort, settings.Cooldown, settings.ConfirmQuit, settings.RefreshInterval, settings.Theme, settings.NoAuth, settings.APIKey, settings.APIUsername)
+	return os.WriteFile(path, []byte(data), 0600) //nosec G306
+}
+
 var (
 	TitleStyle        lipgloss.Style
 	ItemStyle         lipgloss.Style
@@ -131,3 +374,146 @@ func LoadInstance() (string, error) {
 	}
 	return strings.TrimSpace(string(data)), nil
 }
+
+// Profile is a forum saved under a short name, chosen with --profile.
+type Profile struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+func GetProfilesPath() string {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(userConfigDir, "discourse-tui-client", "profiles.json")
+}
+
+// ListProfiles returns the saved profiles sorted by name. A missing file
+// means there are none.
+func ListProfiles() ([]Profile, error) {
+	// #nosec G304
+	data, err := os.ReadFile(GetProfilesPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read profiles file: %w", err)
+	}
+	var profiles []Profile
+	if err := json.Unmarshal(data, &profiles); err != nil {
+		return nil, fmt.Errorf("failed to parse profiles file: %w", err)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// SaveProfile saves instanceURL under name, replacing a profile of the same
+// name.
+func SaveProfile(name, instanceURL string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("profile name cannot be empty")
+	}
+	profiles, err := ListProfiles()
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range profiles {
+		if profiles[i].Name == name {
+			profiles[i].URL = instanceURL
+			replaced = true
+		}
+	}
+	if !replaced {
+		profiles = append(profiles, Profile{Name: name, URL: instanceURL})
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode profiles: %w", err)
+	}
+	path := GetProfilesPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+func LoadProfile(name string) (Profile, error) {
+	profiles, err := ListProfiles()
+	if err != nil {
+		return Profile{}, err
+	}
+	for _, profile := range profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return Profile{}, fmt.Errorf("no profile named %q", name)
+}
+
+// accountInstanceName is the part of an instance URL used in account cookie
+// file names.
+func accountInstanceName(instanceURL string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(instanceURL, "https://"), "http://")
+	name = strings.TrimSuffix(name, "/")
+	return strings.NewReplacer("/", "_", ":", "_").Replace(name)
+}
+
+// AccountCookiesPath is where the cookies of one account on an instance are
+// kept, next to the default cookies.txt.
+func AccountCookiesPath(instanceURL, username string) string {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	name := fmt.Sprintf("cookies-%s-%s.txt", accountInstanceName(instanceURL), username)
+	return filepath.Join(userConfigDir, "discourse-tui-client", name)
+}
+
+// ListAccounts returns the usernames with saved cookies for an instance, sorted.
+func ListAccounts(instanceURL string) ([]string, error) {
+	userConfigDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config directory: %w", err)
+	}
+	prefix := fmt.Sprintf("cookies-%s-", accountInstanceName(instanceURL))
+	matches, err := filepath.Glob(filepath.Join(userConfigDir, "discourse-tui-client", prefix+"*.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+	var accounts []string
+	for _, match := range matches {
+		accounts = append(accounts, strings.TrimSuffix(strings.TrimPrefix(filepath.Base(match), prefix), ".txt"))
+	}
+	sort.Strings(accounts)
+	return accounts, nil
+}
+
+// LoadLastRun reads the time of the last successful run stored at path. A
+// missing file returns the zero time.
+func LoadLastRun(path string) (time.Time, error) {
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("failed to read last run file: %w", err)
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid last run time: %w", err)
+	}
+	return t, nil
+}
+
+func SaveLastRun(path string, t time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return os.WriteFile(path, []byte(t.Format(time.RFC3339)), 0600)
+}
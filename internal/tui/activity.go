@@ -0,0 +1,74 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"git.quad4.io/discourse-tui-client/pkg/discourse"
+)
+
+type activityItem struct {
+	action discourse.UserAction
+}
+
+func (i activityItem) Title() string {
+	if i.action.ActionType == discourse.UserActionNewTopic {
+		return "Started: " + i.action.Title
+	}
+	return "Replied: " + i.action.Title
+}
+
+func (i activityItem) Description() string {
+	desc := fmt.Sprintf("#%d • %s", i.action.PostNumber, i.action.CreatedAt.Local().Format("2006-01-02 15:04"))
+	if excerpt := strings.Join(strings.Fields(html.UnescapeString(convertHTMLToText(i.action.Excerpt))), " "); excerpt != "" {
+		desc += " • " + excerpt
+	}
+	return desc
+}
+
+func (i activityItem) FilterValue() string { return i.action.Title }
+
+// activityModel lists a user's recent topics and replies; choosing one opens
+// the topic at that post.
+type activityModel struct {
+	list list.Model
+}
+
+func newActivityModel(username string, actions []discourse.UserAction, width, height int) activityModel {
+	items := make([]list.Item, len(actions))
+	for i, action := range actions {
+		items[i] = activityItem{action: action}
+	}
+	l := list.New(items, newTopicDelegate(), width-2, height-4)
+	l.Title = "Recent activity of " + username
+	l.SetShowHelp(false)
+	l.SetStatusBarItemName("post", "posts")
+	applyListStyles(&l)
+	return activityModel{list: l}
+}
+
+func (m activityModel) Update(msg tea.Msg) (activityModel, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.list.SetSize(msg.Width-2, msg.Height-4)
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m activityModel) View() string {
+	help := "Enter: open the topic at this post | /: filter | Esc/q: close"
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.list.View(),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Padding(0, 1).Render(help),
+	)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"sort"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"git.quad4.io/discourse-tui-client/internal/config"
+	"git.quad4.io/discourse-tui-client/pkg/discourse"
+)
+
+type categoryItem struct {
+	category discourse.Category
+}
+
+func (i categoryItem) Title() string { return i.category.Name }
+func (i categoryItem) Description() string {
+	return pluralize(i.category.TopicCount, "topic", "topics") + " • " + pluralize(i.category.PostCount, "post", "posts")
+}
+func (i categoryItem) FilterValue() string { return i.category.Name }
+
+// categoryPickerModel lists the forum's categories to browse one of them.
+type categoryPickerModel struct {
+	list       list.Model
+	categories []discourse.Category
+	sort       string
+}
+
+func newCategoryPickerModel(categories []discourse.Category, width, height int) categoryPickerModel {
+	l := list.New(nil, newTopicDelegate(), width-2, height-4)
+	l.SetShowHelp(false)
+	l.SetStatusBarItemName("category", "categories")
+	applyListStyles(&l)
+	m := categoryPickerModel{list: l, categories: categories, sort: config.Current.CategorySort}
+	m.setItems()
+	return m
+}
+
+// setItems fills the list with the categories in the current sort order.
+func (m *categoryPickerModel) setItems() {
+	sorted := sortCategories(m.categories, m.sort)
+	items := make([]list.Item, len(sorted))
+	for i, category := range sorted {
+		items[i] = categoryItem{category: category}
+	}
+	m.list.SetItems(items)
+	m.list.Select(0)
+
+	switch m.sort {
+	case config.CategorySortPosts:
+		m.list.Title = "Categories (most active first)"
+	case config.CategorySortTopics:
+		m.list.Title = "Categories (most topics first)"
+	default:
+		m.list.Title = "Categories"
+	}
+}
+
+// sortCategories returns a copy of categories ordered by the given
+// category_sort value.
+func sortCategories(categories []discourse.Category, by string) []discourse.Category {
+	sorted := append([]discourse.Category(nil), categories...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		switch by {
+		case config.CategorySortPosts:
+			return sorted[i].PostCount > sorted[j].PostCount
+		case config.CategorySortTopics:
+			return sorted[i].TopicCount > sorted[j].TopicCount
+		}
+		return sorted[i].Position < sorted[j].Position
+	})
+	return sorted
+}
+
+func (m categoryPickerModel) Update(msg tea.Msg) (categoryPickerModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width-2, msg.Height-4)
+		return m, nil
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		if msg.String() == "s" {
+			switch m.sort {
+			case config.CategorySortPosts:
+				m.sort = config.CategorySortTopics
+			case config.CategorySortTopics:
+				m.sort = config.CategorySortPosition
+			default:
+				m.sort = config.CategorySortPosts
+			}
+			m.setItems()
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m categoryPickerModel) View() string {
+	help := "Enter: show topics | s: sort by position/activity/topics | /: filter | Esc/q: close"
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.list.View(),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Padding(0, 1).Render(help),
+	)
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"git.quad4.io/discourse-tui-client/internal/config"
+	"git.quad4.io/discourse-tui-client/pkg/discourse"
+)
+
+type chatChannelItem struct {
+	channel discourse.ChatChannel
+}
+
+func (i chatChannelItem) Title() string {
+	if i.channel.DirectMessage {
+		return "@ " + i.channel.Title
+	}
+	return "# " + i.channel.Title
+}
+func (i chatChannelItem) Description() string { return i.channel.Description }
+func (i chatChannelItem) FilterValue() string { return i.channel.Title }
+
+type chatMessagesLoadedMsg struct {
+	channelID int
+	messages  []discourse.ChatMessage
+}
+
+type chatMessagesErrorMsg struct{ err error }
+
+// chatModel is a read-only view of the forum's chat: the list of channels,
+// and the latest messages of the chosen one.
+type chatModel struct {
+	client   *discourse.Client
+	list     list.Model
+	viewport viewport.Model
+	channel  *discourse.ChatChannel
+	messages []discourse.ChatMessage
+	message  string
+}
+
+func newChatModel(client *discourse.Client, channels []discourse.ChatChannel, width, height int) chatModel {
+	items := make([]list.Item, len(channels))
+	for i, channel := range channels {
+		items[i] = chatChannelItem{channel: channel}
+	}
+	l := list.New(items, newTopicDelegate(), width-2, height-4)
+	l.Title = "Chat"
+	l.SetShowHelp(false)
+	l.SetStatusBarItemName("channel", "channels")
+	applyListStyles(&l)
+	return chatModel{client: client, list: l, viewport: viewport.New(width-2, height-5)}
+}
+
+// inChannel reports whether a channel's messages are shown rather than the
+// channel list.
+func (m chatModel) inChannel() bool {
+	return m.channel != nil
+}
+
+func (m chatModel) loadMessages() tea.Cmd {
+	client := m.client
+	channelID := m.channel.ID
+	return func() tea.Msg {
+		messages, err := client.GetChatMessages(channelID)
+		if err != nil {
+			return chatMessagesErrorMsg{err: err}
+		}
+		return chatMessagesLoadedMsg{channelID: channelID, messages: messages}
+	}
+}
+
+func (m chatModel) Update(msg tea.Msg) (chatModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width-2, msg.Height-4)
+		m.viewport.Width = msg.Width - 2
+		m.viewport.Height = msg.Height - 5
+		m.renderMessages()
+		return m, nil
+	case chatMessagesLoadedMsg:
+		if m.channel == nil || m.channel.ID != msg.channelID {
+			return m, nil
+		}
+		m.messages = msg.messages
+		m.message = ""
+		m.renderMessages()
+		m.viewport.GotoBottom()
+		return m, nil
+	case chatMessagesErrorMsg:
+		m.message = "Error loading messages: " + discourse.ErrorMessage(msg.err)
+		return m, nil
+	case tea.KeyMsg:
+		if m.inChannel() {
+			switch msg.String() {
+			case "esc", "q":
+				m.channel = nil
+				m.messages = nil
+				m.message = ""
+				return m, nil
+			case "r":
+				m.message = "Loading messages..."
+				return m, m.loadMessages()
+			}
+			break
+		}
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		if msg.String() == "enter" {
+			item, ok := m.list.SelectedItem().(chatChannelItem)
+			if !ok {
+				return m, nil
+			}
+			channel := item.channel
+			m.channel = &channel
+			m.messages = nil
+			m.message = "Loading messages..."
+			m.renderMessages()
+			return m, m.loadMessages()
+		}
+	}
+
+	var cmd tea.Cmd
+	if m.inChannel() {
+		m.viewport, cmd = m.viewport.Update(msg)
+	} else {
+		m.list, cmd = m.list.Update(msg)
+	}
+	return m, cmd
+}
+
+// renderMessages fills the viewport with the channel's messages.
+func (m *chatModel) renderMessages() {
+	if m.channel == nil {
+		return
+	}
+	if len(m.messages) == 0 {
+		m.viewport.SetContent("No messages")
+		return
+	}
+	var b strings.Builder
+	width := max(m.viewport.Width-2, 10)
+	for _, message := range m.messages {
+		header := fmt.Sprintf("%s  %s", message.Username, message.CreatedAt.Local().Format("2006-01-02 15:04"))
+		b.WriteString(config.TitleStyle.Render(header))
+		b.WriteString("\n")
+		text := message.Message
+		if message.Cooked != "" {
+			text = html.UnescapeString(convertHTMLToText(message.Cooked))
+		}
+		b.WriteString(lipgloss.NewStyle().Width(width).Render(strings.TrimSpace(text)))
+		b.WriteString("\n\n")
+	}
+	m.viewport.SetContent(b.String())
+}
+
+func (m chatModel) View() string {
+	help := "Enter: read channel | /: filter | Esc/q: close"
+	body := m.list.View()
+	if m.inChannel() {
+		help = "↑/↓/PgUp/PgDn: scroll | r: reload | Esc/q: back to channels"
+		body = lipgloss.JoinVertical(lipgloss.Left,
+			config.TitleStyle.Render(chatChannelItem{channel: *m.channel}.Title()),
+			m.viewport.View(),
+		)
+	}
+	if m.message != "" {
+		help = m.message + " | " + help
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		body,
+		lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Padding(0, 1).Render(help),
+	)
+}
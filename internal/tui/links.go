@@ -0,0 +1,184 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"html"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"git.quad4.io/discourse-tui-client/internal/config"
+	"git.quad4.io/discourse-tui-client/pkg/markdown"
+)
+
+type linkItem struct {
+	text string
+	url  string
+}
+
+func (i linkItem) Title() string {
+	if i.text == "" || i.text == i.url {
+		return i.url
+	}
+	return i.text
+}
+func (i linkItem) Description() string { return i.url }
+func (i linkItem) FilterValue() string { return i.text + " " + i.url }
+
+// linksModel lists the links of a post so they can be opened or copied.
+type linksModel struct {
+	list    list.Model
+	message string
+}
+
+func newLinksModel(title string, links []linkItem, width, height int) linksModel {
+	items := make([]list.Item, len(links))
+	for i, link := range links {
+		items[i] = link
+	}
+	l := list.New(items, newTopicDelegate(), width-2, height-4)
+	l.Title = title
+	l.SetShowHelp(false)
+	l.SetStatusBarItemName("link", "links")
+	applyListStyles(&l)
+	return linksModel{list: l}
+}
+
+func (m linksModel) Update(msg tea.Msg) (linksModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width-2, msg.Height-4)
+		return m, nil
+	case tea.KeyMsg:
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		link, ok := m.list.SelectedItem().(linkItem)
+		if !ok {
+			break
+		}
+		switch msg.String() {
+		case "enter", "o":
+			if err := openURL(link.url); err != nil {
+				m.message = err.Error()
+			} else {
+				m.message = "Opened " + link.url
+			}
+			return m, nil
+		case "y":
+			if err := copyToClipboard(link.url); err != nil {
+				m.message = err.Error()
+			} else {
+				m.message = "Copied " + link.url
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m linksModel) View() string {
+	help := "Enter/o: open in browser | y: copy URL | /: filter | Esc/q: close"
+	if m.message != "" {
+		help = m.message + " • " + help
+	}
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.list.View(),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Padding(0, 1).Render(help),
+	)
+}
+
+// trackingParams are query parameters, or prefixes of them, that only
+// serve to track clicks.
+var trackingParams = []string{"utm_", "fbclid", "gclid", "dclid", "msclkid", "mc_cid", "mc_eid", "igshid", "yclid", "_hsenc", "_hsmi"}
+
+// cleanURL drops tracking parameters from u when the strip_tracking setting
+// is on. URLs that don't parse are returned unchanged.
+func cleanURL(u string) string {
+	if !config.Current.StripTracking || !strings.Contains(u, "?") {
+		return u
+	}
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return u
+	}
+	query, err := url.ParseQuery(parsed.RawQuery)
+	if err != nil {
+		// Don't lose parameters we can't parse
+		return u
+	}
+	changed := false
+	for key := range query {
+		for _, param := range trackingParams {
+			if strings.HasPrefix(strings.ToLower(key), param) {
+				query.Del(key)
+				changed = true
+				break
+			}
+		}
+	}
+	if !changed {
+		return u
+	}
+	parsed.RawQuery = query.Encode()
+	return parsed.String()
+}
+
+// cleanHref is cleanURL for an href that still has its HTML entities.
+func cleanHref(href string) string {
+	if !config.Current.StripTracking {
+		return href
+	}
+	return cleanURL(html.UnescapeString(href))
+}
+
+// extractLinks returns the links in a post's cooked HTML in order, with
+// relative URLs resolved against base. Repeated URLs are listed once.
+func extractLinks(cooked, base string) []linkItem {
+	baseURL, _ := url.Parse(base)
+	var links []linkItem
+	seen := make(map[string]bool)
+	var current *linkItem
+	var text strings.Builder
+
+	for i := 0; i < len(cooked); i++ {
+		if cooked[i] != '<' {
+			if current != nil {
+				text.WriteByte(cooked[i])
+			}
+			continue
+		}
+		end := strings.IndexByte(cooked[i:], '>')
+		if end == -1 {
+			break
+		}
+		tag := cooked[i+1 : i+end]
+		i += end
+
+		if href := markdown.HrefFromTag(tag); href != "" {
+			current = &linkItem{url: cleanURL(html.UnescapeString(href))}
+			text.Reset()
+		} else if tag == "/a" && current != nil {
+			current.text = strings.Join(strings.Fields(html.UnescapeString(text.String())), " ")
+			if baseURL != nil {
+				if ref, err := url.Parse(current.url); err == nil {
+					current.url = baseURL.ResolveReference(ref).String()
+				}
+			}
+			// Skip in-page anchors like footnote markers
+			if !strings.HasPrefix(current.url, "#") && !seen[current.url] {
+				seen[current.url] = true
+				links = append(links, *current)
+			}
+			current = nil
+		}
+	}
+	return links
+}
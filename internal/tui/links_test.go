@@ -0,0 +1,38 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"testing"
+
+	"git.quad4.io/discourse-tui-client/internal/config"
+)
+
+func TestCleanURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		strip bool
+		url   string
+		want  string
+	}{
+		{name: "setting off", url: "https://example.com/?utm_source=x", want: "https://example.com/?utm_source=x"},
+		{name: "no query", strip: true, url: "https://example.com/page", want: "https://example.com/page"},
+		{name: "utm dropped", strip: true, url: "https://example.com/?utm_source=x&utm_medium=y", want: "https://example.com/"},
+		{name: "other parameters kept", strip: true, url: "https://example.com/?id=3&fbclid=abc", want: "https://example.com/?id=3"},
+		{name: "case insensitive", strip: true, url: "https://example.com/?UTM_Source=x&q=go", want: "https://example.com/?q=go"},
+		{name: "nothing to drop", strip: true, url: "https://example.com/?b=2&a=1", want: "https://example.com/?b=2&a=1"},
+		{name: "fragment kept", strip: true, url: "https://example.com/?gclid=1#top", want: "https://example.com/#top"},
+		{name: "unparsable query", strip: true, url: "https://example.com/?a=%zz&utm_source=x", want: "https://example.com/?a=%zz&utm_source=x"},
+	}
+	saved := config.Current
+	t.Cleanup(func() { config.Current = saved })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Current.StripTracking = tt.strip
+			if got := cleanURL(tt.url); got != tt.want {
+				t.Errorf("cleanURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"git.quad4.io/discourse-tui-client/internal/config"
+)
+
+var (
+	mdHeading     = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	mdBullet      = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+	mdOrdered     = regexp.MustCompile(`^(\s*)(\d+)[.)]\s+(.*)$`)
+	mdRule        = regexp.MustCompile(`^\s*(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	mdLink        = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	mdBold        = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	mdItalic      = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b`)
+	mdHeadingText = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
+	mdCode        = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	mdQuote       = lipgloss.NewStyle().Foreground(lipgloss.Color("244")).Italic(true)
+)
+
+// renderMarkdown draws the markdown a user is composing the way the post
+// will roughly look once Discourse has cooked it.
+func renderMarkdown(src string, width int) string {
+	if width < 1 {
+		width = 1
+	}
+	wrap := lipgloss.NewStyle().Width(width)
+
+	var lines []string
+	inFence := false
+	for _, line := range strings.Split(src, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			lines = append(lines, mdCode.Render("  "+line))
+			continue
+		}
+
+		switch {
+		case mdRule.MatchString(line):
+			lines = append(lines, strings.Repeat("─", width))
+		case mdHeading.MatchString(line):
+			text := mdHeading.FindStringSubmatch(line)[2]
+			lines = append(lines, wrap.Render(mdHeadingText.Render(renderInline(text))))
+		case strings.HasPrefix(trimmed, ">"):
+			text := strings.TrimSpace(strings.TrimPrefix(trimmed, ">"))
+			lines = append(lines, wrap.Render(mdQuote.Render("│ "+renderInline(text))))
+		case mdBullet.MatchString(line):
+			parts := mdBullet.FindStringSubmatch(line)
+			lines = append(lines, wrap.Render(parts[1]+"• "+renderInline(parts[2])))
+		case mdOrdered.MatchString(line):
+			parts := mdOrdered.FindStringSubmatch(line)
+			lines = append(lines, wrap.Render(parts[1]+parts[2]+". "+renderInline(parts[3])))
+		default:
+			lines = append(lines, wrap.Render(renderInline(line)))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// renderInline styles code spans, links, bold and italic text in a line,
+// leaving the contents of code spans alone.
+func renderInline(line string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(line, "`") {
+		// Odd parts are inside backticks (an unclosed one just shows as text)
+		if i%2 == 1 && i < strings.Count(line, "`") {
+			b.WriteString(mdCode.Render(part))
+			continue
+		}
+		if i%2 == 1 {
+			b.WriteString("`")
+		}
+		part = mdLink.ReplaceAllStringFunc(part, func(link string) string {
+			m := mdLink.FindStringSubmatch(link)
+			switch config.Current.LinkStyle {
+			case config.LinkStyleText:
+				return m[1]
+			case config.LinkStyleURL:
+				return m[2]
+			}
+			return m[1] + " (" + m[2] + ")"
+		})
+		part = mdBold.ReplaceAllStringFunc(part, func(s string) string {
+			m := mdBold.FindStringSubmatch(s)
+			return lipgloss.NewStyle().Bold(true).Render(m[1] + m[2])
+		})
+		part = mdItalic.ReplaceAllStringFunc(part, func(s string) string {
+			m := mdItalic.FindStringSubmatch(s)
+			return lipgloss.NewStyle().Italic(true).Render(m[1] + m[2])
+		})
+		b.WriteString(part)
+	}
+	return b.String()
+}
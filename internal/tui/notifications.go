@@ -0,0 +1,107 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"git.quad4.io/discourse-tui-client/internal/config"
+	"git.quad4.io/discourse-tui-client/pkg/discourse"
+)
+
+// notificationLabels names the common Discourse notification types.
+var notificationLabels = map[int]string{
+	1:  "Mentioned",
+	2:  "Replied",
+	3:  "Quoted",
+	4:  "Edited",
+	5:  "Liked",
+	6:  "Message",
+	7:  "Invited to message",
+	9:  "Posted",
+	10: "Moved post",
+	11: "Linked",
+	12: "Badge",
+	13: "Invited to topic",
+	15: "Group mentioned",
+	17: "New topic",
+	19: "Liked",
+	20: "Post approved",
+	24: "Bookmark reminder",
+	25: "Reaction",
+}
+
+type notificationItem struct {
+	notification discourse.Notification
+}
+
+func (i notificationItem) Title() string {
+	label, ok := notificationLabels[i.notification.NotificationType]
+	if !ok {
+		label = "Notification"
+	}
+	title := label + ": " + i.notification.Title
+	if !i.notification.Read {
+		return config.StatusStyle.Render("● " + title)
+	}
+	return title
+}
+
+func (i notificationItem) Description() string {
+	desc := i.notification.CreatedAt.Local().Format("2006-01-02 15:04")
+	if i.notification.Username != "" {
+		desc = "@" + i.notification.Username + " • " + desc
+	}
+	if i.notification.PostNumber > 0 {
+		desc = fmt.Sprintf("#%d • %s", i.notification.PostNumber, desc)
+	}
+	return desc
+}
+
+func (i notificationItem) FilterValue() string { return i.notification.Title }
+
+// notificationsModel lists the user's notifications; choosing one opens the
+// topic at its post.
+type notificationsModel struct {
+	list list.Model
+}
+
+func newNotificationsModel(notifications []discourse.Notification, width, height int) notificationsModel {
+	items := make([]list.Item, len(notifications))
+	unread := 0
+	for i, notification := range notifications {
+		items[i] = notificationItem{notification: notification}
+		if !notification.Read {
+			unread++
+		}
+	}
+	l := list.New(items, newTopicDelegate(), width-2, height-4)
+	l.Title = fmt.Sprintf("Notifications (%d unread)", unread)
+	l.SetShowHelp(false)
+	l.SetStatusBarItemName("notification", "notifications")
+	applyListStyles(&l)
+	return notificationsModel{list: l}
+}
+
+func (m notificationsModel) Update(msg tea.Msg) (notificationsModel, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.list.SetSize(msg.Width-2, msg.Height-4)
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m notificationsModel) View() string {
+	help := "Enter: open the topic at this post | /: filter | Esc/q: close"
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.list.View(),
+		lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Padding(0, 1).Render(help),
+	)
+}
@@ -0,0 +1,54 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"git.quad4.io/discourse-tui-client/internal/config"
+)
+
+// overlayModel shows read-only text (likers, edit history, ...) over the
+// topic list until it is dismissed.
+type overlayModel struct {
+	title    string
+	viewport viewport.Model
+	// keys lists extra keys the overlay answers to, for the help line.
+	keys string
+}
+
+func newOverlayModel(title, body string, width, height int) overlayModel {
+	vp := viewport.New(width-2, height-6)
+	vp.Style = lipgloss.NewStyle().
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62"))
+	vp.SetContent(body)
+	return overlayModel{title: title, viewport: vp}
+}
+
+func (m overlayModel) Update(msg tea.Msg) (overlayModel, tea.Cmd) {
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.viewport.Width = msg.Width - 2
+		m.viewport.Height = msg.Height - 6
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m overlayModel) View() string {
+	help := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(0, 1).
+		Render(m.keys + "↑/↓: scroll | Esc/q: close")
+	return lipgloss.JoinVertical(lipgloss.Left,
+		config.TitleStyle.Render(m.title),
+		"",
+		m.viewport.View(),
+		help,
+	)
+}
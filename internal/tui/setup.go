@@ -0,0 +1,452 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"git.quad4.io/discourse-tui-client/internal/config"
+	"git.quad4.io/discourse-tui-client/pkg/discourse"
+)
+
+type setupStep int
+
+const (
+	setupInstance setupStep = iota
+	setupAuth
+	setupLogin
+	setupAPIKey
+	setupTheme
+	setupCooldown
+	setupSummary
+)
+
+// Choices of the auth step, in the order of setupAuthOptions.
+const (
+	setupAuthLogin = iota
+	setupAuthAPIKey
+	setupAuthBrowse
+)
+
+var setupAuthOptions = []string{
+	"Log in with username and password",
+	"Use an API key",
+	"Browse without logging in",
+}
+
+// Fields of the wizard's login step; the two-factor one is only added once
+// the forum asks for a code.
+const (
+	setupUsernameField = iota
+	setupPasswordField
+	setupSecondFactorField
+)
+
+type setupPingMsg struct{ err error }
+type setupLoginMsg struct{ err error }
+type setupAPIKeyMsg struct{ err error }
+type setupSavedMsg struct{}
+
+// setupModel is the first-run wizard: it picks the forum and how to log in
+// to it, a color theme and the page cooldown, and saves them at the end.
+type setupModel struct {
+	step          setupStep
+	cookiesPath   string
+	encrypt       bool
+	settingsPath  string
+	client        *discourse.Client
+	instanceInput textinput.Model
+	loginInputs   []textinput.Model
+	loginFocus    int
+	apiInputs     []textinput.Model
+	apiFocus      int
+	cooldownInput textinput.Model
+	authChoice    int
+	themeChoice   int
+	busy          string
+	err           error
+	// Completed and NoAuth report the outcome once the program exits.
+	Completed bool
+	NoAuth    bool
+}
+
+func InitialSetupModel(cookiesPath string, encrypt bool, settingsPath string) *setupModel {
+	instance := textinput.New()
+	instance.Placeholder = "forum.example.com"
+	instance.CharLimit = 100
+	instance.Width = 40
+	instance.Focus()
+
+	username := textinput.New()
+	username.Placeholder = "Username or email"
+	username.CharLimit = 50
+	username.Width = 30
+
+	password := textinput.New()
+	password.Placeholder = "Password"
+	password.CharLimit = 50
+	password.Width = 30
+	password.EchoMode = textinput.EchoPassword
+
+	apiKey := textinput.New()
+	apiKey.Placeholder = "API key"
+	apiKey.CharLimit = 100
+	apiKey.Width = 40
+	apiKey.EchoMode = textinput.EchoPassword
+
+	apiUsername := textinput.New()
+	apiUsername.Placeholder = "Username the key acts as"
+	apiUsername.CharLimit = 50
+	apiUsername.Width = 30
+
+	cooldown := textinput.New()
+	cooldown.Placeholder = config.DefaultSettings.Cooldown.String()
+	cooldown.CharLimit = 10
+	cooldown.Width = 10
+	cooldown.SetValue(config.Current.Cooldown.String())
+
+	return &setupModel{
+		cookiesPath:   cookiesPath,
+		encrypt:       encrypt,
+		settingsPath:  settingsPath,
+		instanceInput: instance,
+		loginInputs:   []textinput.Model{username, password},
+		apiInputs:     []textinput.Model{apiKey, apiUsername},
+		cooldownInput: cooldown,
+	}
+}
+
+// InstanceURL is the forum chosen in the wizard.
+func (m *setupModel) InstanceURL() string {
+	if m.client == nil {
+		return ""
+	}
+	return m.client.BaseURL()
+}
+
+func (m *setupModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m *setupModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case setupPingMsg:
+		m.busy = ""
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m.step = setupAuth
+		return m, nil
+	case setupLoginMsg:
+		m.busy = ""
+		if msg.err != nil {
+			if errors.Is(msg.err, discourse.ErrSecondFactorRequired) && len(m.loginInputs) == setupSecondFactorField {
+				m.loginInputs[m.loginFocus].Blur()
+				m.loginInputs = append(m.loginInputs, newSecondFactorInput())
+				m.loginFocus = setupSecondFactorField
+				m.err = msg.err
+				return m, m.loginInputs[setupSecondFactorField].Focus()
+			}
+			m.err = fmt.Errorf("login failed: %w", msg.err)
+			return m, nil
+		}
+		m.step = setupTheme
+		return m, nil
+	case setupAPIKeyMsg:
+		m.busy = ""
+		if msg.err != nil {
+			m.client.SetAPIKey("", "")
+			m.err = fmt.Errorf("the forum did not accept the API key: %w", msg.err)
+			return m, nil
+		}
+		m.step = setupTheme
+		return m, nil
+	case setupSavedMsg:
+		m.busy = ""
+		m.Completed = true
+		return m, tea.Quit
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyCtrlC || msg.Type == tea.KeyEsc {
+			return m, tea.Quit
+		}
+		if m.busy != "" {
+			return m, nil
+		}
+		m.err = nil
+		switch m.step {
+		case setupInstance:
+			if msg.Type == tea.KeyEnter {
+				return m, m.checkInstance()
+			}
+		case setupAuth:
+			switch msg.String() {
+			case "up", "k":
+				m.authChoice = (m.authChoice + len(setupAuthOptions) - 1) % len(setupAuthOptions)
+			case "down", "j":
+				m.authChoice = (m.authChoice + 1) % len(setupAuthOptions)
+			case "enter":
+				switch m.authChoice {
+				case setupAuthLogin:
+					m.step = setupLogin
+					m.loginFocus = 0
+					return m, m.loginInputs[0].Focus()
+				case setupAuthAPIKey:
+					m.step = setupAPIKey
+					m.apiFocus = 0
+					return m, m.apiInputs[0].Focus()
+				}
+				m.NoAuth = true
+				m.step = setupTheme
+			}
+			return m, nil
+		case setupLogin:
+			switch msg.Type {
+			case tea.KeyTab, tea.KeyShiftTab, tea.KeyUp, tea.KeyDown:
+				m.loginInputs[m.loginFocus].Blur()
+				m.loginFocus = (m.loginFocus + 1) % len(m.loginInputs)
+				return m, m.loginInputs[m.loginFocus].Focus()
+			case tea.KeyEnter:
+				if m.loginFocus < len(m.loginInputs)-1 {
+					m.loginInputs[m.loginFocus].Blur()
+					m.loginFocus++
+					return m, m.loginInputs[m.loginFocus].Focus()
+				}
+				return m, m.login()
+			}
+		case setupAPIKey:
+			switch msg.Type {
+			case tea.KeyTab, tea.KeyShiftTab, tea.KeyUp, tea.KeyDown:
+				m.apiInputs[m.apiFocus].Blur()
+				m.apiFocus = (m.apiFocus + 1) % len(m.apiInputs)
+				return m, m.apiInputs[m.apiFocus].Focus()
+			case tea.KeyEnter:
+				if m.apiFocus < len(m.apiInputs)-1 {
+					m.apiInputs[m.apiFocus].Blur()
+					m.apiFocus++
+					return m, m.apiInputs[m.apiFocus].Focus()
+				}
+				return m, m.checkAPIKey()
+			}
+		case setupTheme:
+			switch msg.String() {
+			case "up", "k":
+				m.themeChoice = (m.themeChoice + len(config.ThemePresets) - 1) % len(config.ThemePresets)
+			case "down", "j":
+				m.themeChoice = (m.themeChoice + 1) % len(config.ThemePresets)
+			case "enter":
+				m.step = setupCooldown
+				return m, m.cooldownInput.Focus()
+			}
+			// Preview the theme on the wizard itself
+			config.UpdateStyles(config.ThemePresets[m.themeChoice].Colors)
+			return m, nil
+		case setupCooldown:
+			if msg.Type == tea.KeyEnter {
+				value := strings.TrimSpace(m.cooldownInput.Value())
+				if value == "" {
+					value = m.cooldownInput.Placeholder
+				}
+				d, err := time.ParseDuration(value)
+				if err != nil || d < 0 {
+					m.err = fmt.Errorf("invalid cooldown %q (e.g. 500ms or 1s)", value)
+					return m, nil
+				}
+				config.Current.Cooldown = d
+				m.step = setupSummary
+				return m, nil
+			}
+		case setupSummary:
+			if msg.Type == tea.KeyEnter {
+				return m, m.save()
+			}
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch m.step {
+	case setupInstance:
+		m.instanceInput, cmd = m.instanceInput.Update(msg)
+	case setupLogin:
+		m.loginInputs[m.loginFocus], cmd = m.loginInputs[m.loginFocus].Update(msg)
+	case setupAPIKey:
+		m.apiInputs[m.apiFocus], cmd = m.apiInputs[m.apiFocus].Update(msg)
+	case setupCooldown:
+		m.cooldownInput, cmd = m.cooldownInput.Update(msg)
+	}
+	return m, cmd
+}
+
+// checkInstance creates the client for the entered URL and pings it.
+func (m *setupModel) checkInstance() tea.Cmd {
+	instanceURL := strings.TrimSpace(m.instanceInput.Value())
+	if instanceURL == "" {
+		m.err = fmt.Errorf("instance URL is required")
+		return nil
+	}
+	client, err := discourse.NewClient(instanceURL, m.cookiesPath, m.encrypt)
+	if err != nil {
+		m.err = fmt.Errorf("failed to create client: %w", err)
+		return nil
+	}
+	client.SetPathPrefix(config.Current.PathPrefix)
+	client.SetAcceptLanguage(config.Current.AcceptLanguage)
+	m.client = client
+	m.busy = "Checking " + client.BaseURL() + "..."
+	return func() tea.Msg {
+		return setupPingMsg{err: client.Ping()}
+	}
+}
+
+func (m *setupModel) login() tea.Cmd {
+	username := m.loginInputs[setupUsernameField].Value()
+	password := m.loginInputs[setupPasswordField].Value()
+	if username == "" || password == "" {
+		m.err = fmt.Errorf("username and password are required")
+		return nil
+	}
+	var token string
+	if len(m.loginInputs) > setupSecondFactorField {
+		token = m.loginInputs[setupSecondFactorField].Value()
+	}
+	m.busy = "Logging in..."
+	client := m.client
+	return func() tea.Msg {
+		return setupLoginMsg{err: client.LoginWithSecondFactor(username, password, token)}
+	}
+}
+
+// checkAPIKey tries the entered API key by asking who it acts as.
+func (m *setupModel) checkAPIKey() tea.Cmd {
+	apiKey := strings.TrimSpace(m.apiInputs[0].Value())
+	apiUsername := strings.TrimSpace(m.apiInputs[1].Value())
+	if apiKey == "" || apiUsername == "" {
+		m.err = fmt.Errorf("API key and username are required")
+		return nil
+	}
+	m.client.SetAPIKey(apiKey, apiUsername)
+	m.busy = "Checking the API key..."
+	client := m.client
+	return func() tea.Msg {
+		_, err := client.GetCurrentUser()
+		return setupAPIKeyMsg{err: err}
+	}
+}
+
+// save writes the chosen forum, access, theme and settings. Nothing is
+// written before the summary is confirmed, except the session cookies a
+// password login stores by itself.
+func (m *setupModel) save() tea.Cmd {
+	switch m.authChoice {
+	case setupAuthAPIKey:
+		config.Current.APIKey = strings.TrimSpace(m.apiInputs[0].Value())
+		config.Current.APIUsername = strings.TrimSpace(m.apiInputs[1].Value())
+	case setupAuthBrowse:
+		config.Current.NoAuth = true
+	}
+	config.Current.Theme = config.ThemePresets[m.themeChoice].Key
+	settings := config.Current
+	client := m.client
+	authChoice := m.authChoice
+	username := m.loginInputs[setupUsernameField].Value()
+	settingsPath := m.settingsPath
+	m.busy = "Saving..."
+	return func() tea.Msg {
+		if authChoice == setupAuthLogin {
+			saveLogin(client, client.BaseURL(), username)
+		} else if err := config.SaveInstance(client.BaseURL()); err != nil {
+			log.Printf("Failed to save instance URL: %v", err)
+		}
+		if err := config.SaveSettings(settingsPath, settings); err != nil {
+			log.Printf("Failed to save settings: %v", err)
+		}
+		return setupSavedMsg{}
+	}
+}
+
+func (m *setupModel) View() string {
+	if m.Completed {
+		return "Setup complete!\n"
+	}
+
+	var s strings.Builder
+	s.WriteString(config.TitleStyle.Render("Discourse TUI setup"))
+	s.WriteString("\n\n")
+
+	help := "Enter: continue | Esc: quit"
+	switch m.step {
+	case setupInstance:
+		s.WriteString("Which forum do you want to read?\n\n")
+		s.WriteString(m.instanceInput.View())
+	case setupAuth:
+		s.WriteString("How do you want to use " + m.client.BaseURL() + "?\n\n")
+		s.WriteString(renderChoices(setupAuthOptions, m.authChoice))
+		help = "↑/↓: choose | " + help
+	case setupLogin:
+		s.WriteString("Log in to " + m.client.BaseURL() + "\n\n")
+		for _, input := range m.loginInputs {
+			s.WriteString(input.View() + "\n")
+		}
+		help = "Tab: switch fields | " + help
+	case setupAPIKey:
+		s.WriteString("API key for " + m.client.BaseURL() + " (an admin can create one under Admin > API)\n\n")
+		for _, input := range m.apiInputs {
+			s.WriteString(input.View() + "\n")
+		}
+		help = "Tab: switch fields | " + help
+	case setupTheme:
+		s.WriteString("Pick a color theme (it can be edited later with T):\n\n")
+		names := make([]string, len(config.ThemePresets))
+		for i, preset := range config.ThemePresets {
+			names[i] = preset.Name
+		}
+		s.WriteString(renderChoices(names, m.themeChoice))
+		help = "↑/↓: choose | " + help
+	case setupCooldown:
+		s.WriteString("Pause between page fetches, to go easy on the forum:\n\n")
+		s.WriteString(m.cooldownInput.View())
+	case setupSummary:
+		auth := "logged in"
+		help = "Enter: save and start | Esc: quit without saving settings (you stay logged in)"
+		switch m.authChoice {
+		case setupAuthAPIKey:
+			auth = "API key acting as " + strings.TrimSpace(m.apiInputs[1].Value())
+			help = "Enter: save and start | Esc: quit without saving"
+		case setupAuthBrowse:
+			auth = "without logging in (set no_auth=false in settings.txt to log in later)"
+			help = "Enter: save and start | Esc: quit without saving"
+		}
+		fmt.Fprintf(&s, "Forum:    %s\nAccess:   %s\nTheme:    %s\nCooldown: %s\n",
+			m.client.BaseURL(), auth, config.ThemePresets[m.themeChoice].Name, config.Current.Cooldown)
+	}
+
+	if m.busy != "" {
+		s.WriteString("\n\n" + config.StatusStyle.Render(m.busy))
+	} else if m.err != nil {
+		s.WriteString("\n\n" + config.ErrorStyle.Render(discourse.ErrorMessage(m.err)))
+	}
+	s.WriteString("\n\n" + help)
+	return s.String()
+}
+
+// renderChoices lists options with the chosen one highlighted.
+func renderChoices(options []string, chosen int) string {
+	var s strings.Builder
+	for i, option := range options {
+		if i == chosen {
+			s.WriteString(config.SelectedItemStyle.Render("> "+option) + "\n")
+		} else {
+			s.WriteString(config.ItemStyle.Render("  "+option) + "\n")
+		}
+	}
+	return s.String()
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"runtime"
+
+	"github.com/atotto/clipboard"
+)
+
+// openURL opens u in the user's default browser. Only absolute http and
+// https URLs are handed over, since links come from remote post HTML and
+// the system openers will happily run anything else.
+func openURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("refusing to open %q: not an http(s) link", u)
+	}
+	u = parsed.String()
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.Command("xdg-open", u)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open browser: %w", err)
+	}
+	// Don't leave a zombie behind once the opener exits
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// copyToClipboard puts text on the system clipboard.
+func copyToClipboard(text string) error {
+	if err := clipboard.WriteAll(text); err != nil {
+		return fmt.Errorf("failed to copy to clipboard: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"git.quad4.io/discourse-tui-client/internal/config"
+)
+
+var colorValuePattern = regexp.MustCompile(`^(#[0-9a-fA-F]{3}|#[0-9a-fA-F]{6}|[0-9]{1,3})$`)
+
+var themeFields = []string{"title", "item", "selected", "status", "error"}
+
+// themeEditorModel edits the five colors from colors.txt. Every valid change
+// is applied to the styles immediately so the list underneath previews it.
+type themeEditorModel struct {
+	inputs     []textinput.Model
+	focusIndex int
+	original   config.ColorConfig
+	err        error
+}
+
+func newThemeEditorModel(colors config.ColorConfig) themeEditorModel {
+	values := []string{colors.Title, colors.Item, colors.Selected, colors.Status, colors.Error}
+	inputs := make([]textinput.Model, len(themeFields))
+	for i, field := range themeFields {
+		ti := textinput.New()
+		ti.Prompt = fmt.Sprintf("%-9s", field+":")
+		ti.Placeholder = "#RRGGBB or ANSI code"
+		ti.CharLimit = 7
+		ti.Width = 20
+		ti.SetValue(values[i])
+		inputs[i] = ti
+	}
+	inputs[0].Focus()
+
+	return themeEditorModel{
+		inputs:   inputs,
+		original: colors,
+	}
+}
+
+// colors returns the edited colors, or an error naming the first invalid one.
+func (m themeEditorModel) colors() (config.ColorConfig, error) {
+	values := make([]string, len(m.inputs))
+	for i, input := range m.inputs {
+		values[i] = strings.TrimSpace(input.Value())
+		if !colorValuePattern.MatchString(values[i]) {
+			return config.ColorConfig{}, fmt.Errorf("invalid %s color %q", themeFields[i], values[i])
+		}
+	}
+	return config.ColorConfig{
+		Title:    values[0],
+		Item:     values[1],
+		Selected: values[2],
+		Status:   values[3],
+		Error:    values[4],
+	}, nil
+}
+
+func (m themeEditorModel) Update(msg tea.Msg) (themeEditorModel, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.Type {
+		case tea.KeyTab, tea.KeyDown:
+			m.focusIndex = (m.focusIndex + 1) % len(m.inputs)
+			return m, m.focus()
+		case tea.KeyShiftTab, tea.KeyUp:
+			m.focusIndex = (m.focusIndex - 1 + len(m.inputs)) % len(m.inputs)
+			return m, m.focus()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.focusIndex], cmd = m.inputs[m.focusIndex].Update(msg)
+
+	colors, err := m.colors()
+	m.err = err
+	if err == nil {
+		config.UpdateStyles(colors)
+	}
+	return m, cmd
+}
+
+func (m *themeEditorModel) focus() tea.Cmd {
+	var cmd tea.Cmd
+	for i := range m.inputs {
+		if i == m.focusIndex {
+			cmd = m.inputs[i].Focus()
+		} else {
+			m.inputs[i].Blur()
+		}
+	}
+	return cmd
+}
+
+func (m themeEditorModel) View() string {
+	var b strings.Builder
+	b.WriteString(config.TitleStyle.Render("Edit Colors"))
+	b.WriteString("\n\n")
+	for _, input := range m.inputs {
+		b.WriteString(input.View())
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	if m.err != nil {
+		b.WriteString(config.ErrorStyle.Render(m.err.Error()))
+	} else {
+		b.WriteString(config.StatusStyle.Render("Status messages look like this"))
+	}
+	b.WriteString("\n\nTab/Shift+Tab: navigate | Enter: save | Esc: cancel")
+	return b.String()
+}
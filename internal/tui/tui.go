@@ -4,49 +4,130 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/microcosm-cc/bluemonday"
 
 	"git.quad4.io/discourse-tui-client/internal/config"
 	"git.quad4.io/discourse-tui-client/pkg/discourse"
+	"git.quad4.io/discourse-tui-client/pkg/markdown"
 )
 
 type topicItem struct {
 	topic discourse.Topic
+	width int
 }
 
 func (i topicItem) Title() string {
 	var title strings.Builder
+	if i.topic.Closed {
+		title.WriteString("🔒 ")
+	}
+	if i.topic.Archived {
+		title.WriteString("📦 ")
+	}
+	if i.topic.IsPinned() {
+		title.WriteString("📌 ")
+	}
+	if i.topic.Bookmarked {
+		title.WriteString("★ ")
+	}
+	if i.topic.HasAcceptedAnswer && config.Current.ShowSolved {
+		title.WriteString("✓ ")
+	}
+	if i.topic.ImageURL != "" && config.Current.ShowThumbnails {
+		title.WriteString("🖼 ")
+	}
 	title.WriteString(i.topic.Title)
 
-	if i.topic.CategoryName != "" {
-		title.WriteString(" [")
-		title.WriteString(i.topic.CategoryName)
-		title.WriteString("]")
+	var suffix strings.Builder
+	if category := categoryLabel(i.topic); category != "" {
+		suffix.WriteString(" [")
+		suffix.WriteString(category)
+		suffix.WriteString("]")
 	}
 
 	if len(i.topic.Tags) > 0 {
-		title.WriteString(" {")
-		title.WriteString(strings.Join(i.topic.Tags, ", "))
-		title.WriteString("}")
+		suffix.WriteString(" {")
+		suffix.WriteString(strings.Join(i.topic.Tags, ", "))
+		suffix.WriteString("}")
+	}
+
+	// Shorten the title rather than the category and tags after it
+	if i.width > 0 && lipgloss.Width(title.String())+lipgloss.Width(suffix.String()) > i.width {
+		short := ansi.Truncate(title.String(), max(i.width-lipgloss.Width(suffix.String()), 1), "…")
+		title.Reset()
+		title.WriteString(short)
 	}
+	title.WriteString(suffix.String())
 
 	return title.String()
 }
 
+// categoryLabel returns the text shown in a topic's category brackets. Topics
+// whose category wasn't in the category list get a placeholder so they don't
+// look different from the rest, unless the user chose to hide it. Personal
+// messages have no category and are labelled "PM".
+func categoryLabel(topic discourse.Topic) string {
+	if topic.IsPrivateMessage() {
+		return "PM"
+	}
+	if topic.CategoryName != "" {
+		return topic.CategoryName
+	}
+	if topic.CategoryID == 0 {
+		return ""
+	}
+	switch config.Current.UnknownCategory {
+	case "hide":
+		return ""
+	case "id":
+		return fmt.Sprintf("#%d", topic.CategoryID)
+	default:
+		return "uncategorized"
+	}
+}
+
 func (i topicItem) Description() string {
-	return fmt.Sprintf("%d replies • %d views", i.topic.ReplyCount, i.topic.Views)
+	desc := pluralize(i.topic.Replies(), "reply", "replies")
+	if creator := i.topic.CreatorUsername; creator != "" {
+		by := "by " + creator
+		if last := i.topic.LastPosterUsername; last != "" && last != creator && i.topic.Replies() > 0 {
+			by += ", last reply " + last
+		}
+		desc = by + " • " + desc
+	}
+	if i.topic.PostsCount > 0 {
+		desc += " • " + pluralize(i.topic.PostsCount, "post", "posts")
+	}
+	if i.topic.UnreadPosts > 0 {
+		desc += fmt.Sprintf(" • %d unread", i.topic.UnreadPosts)
+	}
+	return desc + " • " + pluralize(i.topic.Views, "view", "views")
+}
+
+func pluralize(n int, singular, plural string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, singular)
+	}
+	return fmt.Sprintf("%d %s", n, plural)
 }
 
 func (i topicItem) FilterValue() string { return i.topic.Title }
@@ -57,18 +138,95 @@ const (
 	stateTopicList modelState = iota
 	stateNewTopic
 	stateLogin
+	stateThemeEditor
+	stateOverlay
+	stateLinks
+	stateCategories
+	stateActivity
+	stateChat
+	stateNotifications
+)
+
+const (
+	viewLatest    = "latest"
+	viewSearch    = "search"
+	viewBookmarks = "bookmarks"
+	viewCategory  = "category"
+	viewHot       = "hot"
+	viewTop       = "top"
+	viewNew       = "new"
+	viewUnread    = "unread"
 )
 
+// sortKeys maps the number keys to the topic lists they switch to.
+var sortKeys = map[string]string{
+	"1": viewLatest,
+	"2": viewTop,
+	"3": viewNew,
+	"4": viewUnread,
+}
+
+// topicView remembers what a list view had loaded so that switching away and
+// back continues from the same page and selection instead of starting over.
+type topicView struct {
+	topics        []discourse.Topic
+	moreTopicsURL string
+	selected      int
+}
+
 type topicCreatedMsg struct {
 	post    *discourse.Post
 	message string
 }
 type topicCreateErrorMsg struct{ err error }
 
+type openTopicMsg struct{ topicID int }
+
+type topicUpdatedMsg struct {
+	topicID    int
+	title      string
+	categoryID int
+	tags       []string
+}
+
+type replyCreatedMsg struct {
+	post    *discourse.Post
+	message string
+}
+
 type postsLoadedMsg struct {
-	posts *discourse.TopicResponse
+	topicID int
+	posts   *discourse.TopicResponse
+	topic   *discourse.Topic
+	// full is set once every post of the topic has been fetched.
+	full bool
+	// window is set for posts around jumpToPost rather than from the start.
+	window bool
+	// next waits for the following chunk while the topic streams in.
+	next tea.Cmd
+}
+type postsLoadErrorMsg struct {
+	topicID int
+	err     error
+}
+
+type likersLoadedMsg struct {
+	post  discourse.Post
+	users []discourse.User
+}
+type likersLoadErrorMsg struct{ err error }
+
+type revisionLoadedMsg struct {
+	post     discourse.Post
+	revision *discourse.Revision
 }
-type postsLoadErrorMsg struct{ err error }
+type revisionLoadErrorMsg struct{ err error }
+
+type postJSONLoadedMsg struct {
+	post discourse.Post
+	data []byte
+}
+type postJSONLoadErrorMsg struct{ err error }
 
 type topicsRefreshedMsg struct {
 	response *discourse.Response
@@ -77,6 +235,7 @@ type topicsRefreshErrorMsg struct{ err error }
 
 type moreTopicsLoadedMsg struct {
 	response *discourse.Response
+	view     string
 }
 type moreTopicsLoadErrorMsg struct{ err error }
 
@@ -85,6 +244,120 @@ type loadAllTopicsMsg struct {
 }
 type loadAllTopicsErrorMsg struct{ err error }
 
+type prefetchDoneMsg struct {
+	fetched int
+}
+
+// rateLimitTickMsg keeps the rate limit countdown in the header current.
+type rateLimitTickMsg struct{}
+
+// cooldownTickMsg polls whether a bulk fetch is waiting out the page cooldown.
+type cooldownTickMsg struct{}
+
+type currentUserLoadedMsg struct {
+	user *discourse.UserProfile
+}
+
+type topicStatusUpdatedMsg struct {
+	topicID int
+	status  string
+	enabled bool
+}
+type topicStatusErrorMsg struct{ err error }
+
+type topicMarkedReadMsg struct {
+	topicID    int
+	postNumber int
+}
+type topicMarkReadErrorMsg struct{ err error }
+
+type topicMarkedUnreadMsg struct {
+	topicID    int
+	postNumber int
+}
+type topicMarkUnreadErrorMsg struct{ err error }
+
+type userProfileLoadedMsg struct {
+	user *discourse.UserProfile
+}
+type userProfileLoadErrorMsg struct{ err error }
+
+type userFollowedMsg struct {
+	username string
+	follow   bool
+}
+type userFollowErrorMsg struct{ err error }
+
+type userActivityLoadedMsg struct {
+	username string
+	actions  []discourse.UserAction
+}
+type userActivityLoadErrorMsg struct{ err error }
+
+type notificationsLoadedMsg struct {
+	notifications []discourse.Notification
+}
+type notificationsLoadErrorMsg struct{ err error }
+
+type bookmarksLoadedMsg struct {
+	response *discourse.Response
+}
+type bookmarksLoadErrorMsg struct{ err error }
+
+type categoryTopicsLoadedMsg struct {
+	name        string
+	description string
+	response    *discourse.Response
+}
+type categoryTopicsLoadErrorMsg struct{ err error }
+
+type hotTopicsLoadedMsg struct {
+	response *discourse.Response
+}
+type hotTopicsLoadErrorMsg struct{ err error }
+
+type sortedTopicsLoadedMsg struct {
+	sort     string
+	response *discourse.Response
+}
+type sortedTopicsLoadErrorMsg struct {
+	sort string
+	err  error
+}
+
+type categoriesLoadedMsg struct {
+	categories []discourse.Category
+}
+type categoriesLoadErrorMsg struct{ err error }
+
+type chatChannelsLoadedMsg struct {
+	channels []discourse.ChatChannel
+}
+type chatChannelsLoadErrorMsg struct{ err error }
+
+type postBookmarkedMsg struct {
+	post       discourse.Post
+	reminderAt *time.Time
+}
+type postBookmarkErrorMsg struct{ err error }
+
+type topicBookmarkToggledMsg struct {
+	topicID    int
+	bookmarked bool
+}
+type topicBookmarkErrorMsg struct{ err error }
+
+type postLikeToggledMsg struct {
+	postID  int
+	liked   bool
+	updated *discourse.Post
+}
+type postLikeErrorMsg struct{ err error }
+
+type topicSearchResultsMsg struct {
+	response *discourse.Response
+}
+
 type searchResultsMsg struct {
 	response *discourse.SearchResponse
 }
@@ -101,44 +374,134 @@ type newTopicModel struct {
 	err           error
 	submitting    bool
 	message       string
+	categories    []discourse.Category
+	preview       bool
+	previewText   string
+	previewGen    int
+	// keepOpen is set when the topic being submitted should leave the
+	// composer open, cleared for the next one.
+	keepOpen bool
+	// replyTopicID is set when the form is a reply composer rather than a
+	// new topic; replyTo is the post being answered, if any.
+	replyTopicID int
+	replyTitle   string
+	replyTo      *discourse.Post
+	// editTopicID is set when the form edits an existing topic's title,
+	// category and tags.
+	editTopicID int
+	// confirmDiscard is set while asking whether to throw the draft away.
+	confirmDiscard bool
 }
 
+// previewTickMsg re-renders the composer preview once typing pauses; only the
+// tick matching newTopicModel.previewGen is acted on.
+type previewTickMsg struct{ gen int }
+
+const previewDebounce = 200 * time.Millisecond
+
+// Below minComposerWidth columns the composer asks for a wider terminal
+// instead of drawing inputs that don't fit.
+const (
+	minComposerWidth = 30
+	minInputWidth    = 10
+	minContentHeight = 3
+)
+
 func InitialNewTopicModel(client *discourse.Client, width, height int) newTopicModel {
 	ti := textinput.New()
 	ti.Placeholder = "Topic Title"
 	ti.Focus()
 	ti.CharLimit = 250
-	ti.Width = width - 4
 
 	ta := textarea.New()
 	ta.Placeholder = "Topic content..."
-	ta.SetWidth(width - 4)
-	ta.SetHeight(height / 3)
 
 	ci := textinput.New()
 	ci.Placeholder = "Category ID (e.g., 10)"
 	ci.CharLimit = 10
-	ci.Width = width - 4
 
 	tgi := textinput.New()
 	tgi.Placeholder = "Tags (comma-separated, e.g., go,tui)"
 	tgi.CharLimit = 255
-	tgi.Width = width - 4
+
+	var categories []discourse.Category
+	if client != nil {
+		if response, err := client.GetCategories(); err == nil {
+			categories = response.CategoryList.Categories
+		}
+	}
 
 	n := newTopicModel{
 		client:        client,
+		categories:    categories,
 		titleInput:    ti,
 		contentInput:  ta,
 		categoryInput: ci,
 		tagsInput:     tgi,
 		focusIndex:    0,
-		width:         width,
-		height:        height,
 	}
+	n.resize(width, height)
+	n.updateFocus()
+	return n
+}
+
+// InitialReplyModel is the composer for a reply to topicID, threaded under
+// replyTo unless it is nil.
+func InitialReplyModel(client *discourse.Client, topicID int, topicTitle string, replyTo *discourse.Post, width, height int) newTopicModel {
+	ta := textarea.New()
+	ta.Placeholder = "Reply..."
+
+	n := newTopicModel{
+		client:       client,
+		contentInput: ta,
+		focusIndex:   1,
+		replyTopicID: topicID,
+		replyTitle:   topicTitle,
+		replyTo:      replyTo,
+	}
+	n.resize(width, height)
 	n.updateFocus()
 	return n
 }
 
+// resize fits the inputs to a width x height area, never letting them
+// shrink below a usable size.
+func (m *newTopicModel) resize(width, height int) {
+	m.width, m.height = width, height
+	m.titleInput.Width = m.inputWidth()
+	m.categoryInput.Width = m.inputWidth()
+	m.tagsInput.Width = m.inputWidth()
+
+	contentHeight := height / 3
+	if m.replyTopicID != 0 {
+		contentHeight = height / 2
+	}
+	m.contentInput.SetHeight(max(contentHeight, minContentHeight))
+	if m.preview {
+		m.contentInput.SetWidth(m.previewWidth())
+		m.previewText = renderMarkdown(m.contentInput.Value(), m.previewWidth())
+	} else {
+		m.contentInput.SetWidth(m.inputWidth())
+	}
+}
+
+func (m newTopicModel) inputWidth() int {
+	return max(m.width-4, minInputWidth)
+}
+
+// InitialEditTopicModel is the form for changing an existing topic's title,
+// category and tags.
+func InitialEditTopicModel(client *discourse.Client, topic discourse.Topic, width, height int) newTopicModel {
+	n := InitialNewTopicModel(client, width, height)
+	n.editTopicID = topic.ID
+	n.titleInput.SetValue(topic.Title)
+	if topic.CategoryID != 0 {
+		n.categoryInput.SetValue(strconv.Itoa(topic.CategoryID))
+	}
+	n.tagsInput.SetValue(strings.Join(topic.Tags, ", "))
+	return n
+}
+
 func (m *newTopicModel) Init() tea.Cmd {
 	return textinput.Blink
 }
@@ -178,49 +541,58 @@ func (m *newTopicModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if key, ok := msg.(tea.KeyMsg); ok && key.Paste {
+		pasted := strings.ReplaceAll(string(key.Runes), "\r\n", "\n")
+		pasted = strings.ReplaceAll(pasted, "\r", "\n")
+		if m.focusIndex != 1 {
+			// Title, category and tags are single-line
+			pasted = joinLines(pasted)
+		}
+		key.Runes = []rune(pasted)
+		msg = key
+	}
+
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.resize(msg.Width, msg.Height-4)
+		return m, nil
+	case previewTickMsg:
+		if msg.gen == m.previewGen && m.preview {
+			m.previewText = renderMarkdown(m.contentInput.Value(), m.previewWidth())
+		}
+		return m, nil
 	case tea.KeyMsg:
 		switch msg.Type {
-		case tea.KeyCtrlS:
-			m.submitting = true
-			m.message = "Submitting new topic..."
-			title := m.titleInput.Value()
-			content := m.contentInput.Value()
-			categoryStr := m.categoryInput.Value()
-			tagsStr := m.tagsInput.Value()
-
-			if title == "" || content == "" || categoryStr == "" {
-				m.err = fmt.Errorf("title, content, and category ID are required")
-				m.submitting = false
-				m.message = ""
-				return m, nil
+		case tea.KeyCtrlP:
+			m.preview = !m.preview
+			if m.preview {
+				m.contentInput.SetWidth(m.previewWidth())
+				m.previewText = renderMarkdown(m.contentInput.Value(), m.previewWidth())
+			} else {
+				m.contentInput.SetWidth(m.inputWidth())
 			}
-
-			categoryID, err := strconv.Atoi(categoryStr)
-			if err != nil {
-				m.err = fmt.Errorf("invalid category ID: %w", err)
-				m.submitting = false
-				m.message = ""
-				return m, nil
+			return m, nil
+		case tea.KeyCtrlS:
+			if m.replyTopicID != 0 {
+				return m, m.submitReply()
 			}
-
-			var tags []string
-			if strings.TrimSpace(tagsStr) != "" {
-				tags = strings.Split(tagsStr, ",")
-				for i := range tags {
-					tags[i] = strings.TrimSpace(tags[i])
-				}
+			if m.editTopicID != 0 {
+				return m, m.submitEdit()
 			}
-
-			return m, func() tea.Msg {
-				post, err := m.client.CreateTopic(title, content, categoryID, tags)
-				if err != nil {
-					return topicCreateErrorMsg{err: err}
-				}
-				return topicCreatedMsg{post: post, message: fmt.Sprintf("Topic '%s' created!", post.TopicSlug)}
+			m.keepOpen = false
+			return m, m.submitTopic()
+		case tea.KeyCtrlO:
+			if m.replyTopicID != 0 || m.editTopicID != 0 {
+				break
 			}
+			m.keepOpen = true
+			return m, m.submitTopic()
 
 		case tea.KeyTab, tea.KeyShiftTab:
+			if m.replyTopicID != 0 {
+				// The reply composer only has the content field
+				break
+			}
 			if msg.Type == tea.KeyShiftTab {
 				m.focusIndex--
 			} else {
@@ -233,6 +605,14 @@ func (m *newTopicModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.focusIndex < 0 {
 				m.focusIndex = 3
 			}
+			if m.editTopicID != 0 && m.focusIndex == 1 {
+				// Editing a topic leaves its first post alone
+				if msg.Type == tea.KeyShiftTab {
+					m.focusIndex = 0
+				} else {
+					m.focusIndex = 2
+				}
+			}
 			m.updateFocus()
 			var blinkCmd tea.Cmd
 			if m.focusIndex == 1 {
@@ -249,9 +629,23 @@ func (m *newTopicModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case 0:
 		m.titleInput, cmd = m.titleInput.Update(msg)
 	case 1:
+		before := m.contentInput.Value()
 		m.contentInput, cmd = m.contentInput.Update(msg)
+		if m.preview && m.contentInput.Value() != before {
+			m.previewGen++
+			gen := m.previewGen
+			cmds = append(cmds, tea.Tick(previewDebounce, func(time.Time) tea.Msg {
+				return previewTickMsg{gen: gen}
+			}))
+		}
 	case 2:
 		m.categoryInput, cmd = m.categoryInput.Update(msg)
+		// Point out a category we can't post in while it's being typed
+		if id, err := strconv.Atoi(m.categoryInput.Value()); err == nil && len(m.categories) > 0 {
+			if _, err := discourse.CheckNewTopicCategory(m.categories, id); err != nil {
+				m.err = err
+			}
+		}
 	case 3:
 		m.tagsInput, cmd = m.tagsInput.Update(msg)
 	}
@@ -260,28 +654,243 @@ func (m *newTopicModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// joinLines joins the lines of a paste with single spaces for a single-line
+// field, trimming each line but keeping the spacing within it.
+func joinLines(s string) string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, " ")
+}
+
+// submitTopic creates the composed topic.
+func (m *newTopicModel) submitTopic() tea.Cmd {
+	m.submitting = true
+	m.message = "Submitting new topic..."
+	if m.keepOpen {
+		m.message = "Submitting new topic (the composer stays open)..."
+	}
+	title := m.titleInput.Value()
+	content := m.contentInput.Value()
+	categoryStr := m.categoryInput.Value()
+	tagsStr := m.tagsInput.Value()
+
+	if title == "" || content == "" || categoryStr == "" {
+		m.err = fmt.Errorf("title, content, and category ID are required")
+		m.submitting = false
+		m.message = ""
+		return nil
+	}
+
+	categoryID, err := strconv.Atoi(categoryStr)
+	if err != nil {
+		m.err = fmt.Errorf("invalid category ID: %w", err)
+		m.submitting = false
+		m.message = ""
+		return nil
+	}
+
+	var tags []string
+	if strings.TrimSpace(tagsStr) != "" {
+		tags = strings.Split(tagsStr, ",")
+		for i := range tags {
+			tags[i] = strings.TrimSpace(tags[i])
+		}
+	}
+
+	if err := m.client.ValidateNewTopic(categoryID, tags); err != nil {
+		m.err = err
+		m.submitting = false
+		m.message = ""
+		return nil
+	}
+
+	content, removed := discourse.SanitizeRaw(content)
+	return func() tea.Msg {
+		post, err := m.client.CreateTopic(title, content, categoryID, tags)
+		if err != nil {
+			return topicCreateErrorMsg{err: err}
+		}
+		message := fmt.Sprintf("Topic '%s' created!", post.TopicSlug)
+		if removed > 0 {
+			message += fmt.Sprintf(" (%s removed from the content)", pluralize(removed, "control character was", "control characters were"))
+		}
+		return topicCreatedMsg{post: post, message: message}
+	}
+}
+
+// submitEdit saves the edited title, category and tags.
+func (m *newTopicModel) submitEdit() tea.Cmd {
+	title := strings.TrimSpace(m.titleInput.Value())
+	if title == "" {
+		m.err = fmt.Errorf("the title is required")
+		return nil
+	}
+	// An empty category leaves it as it is
+	categoryID := 0
+	if value := strings.TrimSpace(m.categoryInput.Value()); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			m.err = fmt.Errorf("invalid category ID: %w", err)
+			return nil
+		}
+		categoryID = id
+	}
+	var tags []string
+	for _, tag := range strings.Split(m.tagsInput.Value(), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	m.submitting = true
+	m.message = "Saving topic..."
+	topicID := m.editTopicID
+	client := m.client
+	return func() tea.Msg {
+		if err := client.UpdateTopic(topicID, title, categoryID, tags); err != nil {
+			return topicCreateErrorMsg{err: err}
+		}
+		return topicUpdatedMsg{topicID: topicID, title: title, categoryID: categoryID, tags: tags}
+	}
+}
+
+// hasDraft reports whether the composer holds text that would be lost by
+// leaving it.
+func (m *newTopicModel) hasDraft() bool {
+	if m.submitting {
+		return false
+	}
+	if strings.TrimSpace(m.contentInput.Value()) != "" {
+		return true
+	}
+	return m.editTopicID == 0 && m.replyTopicID == 0 && strings.TrimSpace(m.titleInput.Value()) != ""
+}
+
+// clearForNext empties the composer for another topic, keeping the category
+// since topics created in a row usually share one.
+func (m *newTopicModel) clearForNext() {
+	m.titleInput.Reset()
+	m.contentInput.Reset()
+	m.tagsInput.Reset()
+	m.previewText = ""
+	m.focusIndex = 0
+	m.updateFocus()
+}
+
+// submitReply posts the composed reply.
+func (m *newTopicModel) submitReply() tea.Cmd {
+	content := m.contentInput.Value()
+	if strings.TrimSpace(content) == "" {
+		m.err = fmt.Errorf("the reply is empty")
+		return nil
+	}
+	m.submitting = true
+	m.message = "Posting reply..."
+
+	topicID := m.replyTopicID
+	replyToPostNumber := 0
+	if m.replyTo != nil {
+		replyToPostNumber = m.replyTo.PostNumber
+	}
+	content, removed := discourse.SanitizeRaw(content)
+	client := m.client
+	return func() tea.Msg {
+		post, err := client.CreatePost(topicID, content, replyToPostNumber)
+		if err != nil {
+			return topicCreateErrorMsg{err: err}
+		}
+		message := fmt.Sprintf("Reply posted as #%d", post.PostNumber)
+		if removed > 0 {
+			message += fmt.Sprintf(" (%s removed from the content)", pluralize(removed, "control character was", "control characters were"))
+		}
+		return replyCreatedMsg{post: post, message: message}
+	}
+}
+
+// previewWidth is the width of the content box and of the preview beside it.
+func (m newTopicModel) previewWidth() int {
+	return max((m.width-8)/2, minInputWidth)
+}
+
+// previewView renders the markdown preview in a box as tall as the content
+// box, cutting off what doesn't fit.
+func (m newTopicModel) previewView() string {
+	height := m.contentInput.Height()
+	lines := strings.Split(m.previewText, "\n")
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	return lipgloss.NewStyle().
+		Width(m.previewWidth()).
+		Height(height).
+		PaddingLeft(1).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(lipgloss.Color("62")).
+		Render(strings.Join(lines, "\n"))
+}
+
 func (m newTopicModel) View() string {
+	if m.width < minComposerWidth {
+		return config.ErrorStyle.Render(fmt.Sprintf("Widen your terminal to compose (at least %d columns)", minComposerWidth)) + "\n\nEsc: cancel"
+	}
 	var b strings.Builder
-	b.WriteString(config.TitleStyle.Render("Create New Topic"))
-	b.WriteString("\n\n")
-	b.WriteString(m.titleInput.View())
-	b.WriteString("\n\n")
-	b.WriteString(m.contentInput.View())
-	b.WriteString("\n\n")
-	b.WriteString(m.categoryInput.View())
-	b.WriteString("\n\n")
-	b.WriteString(m.tagsInput.View())
-	b.WriteString("\n\n")
+	if m.replyTopicID != 0 {
+		b.WriteString(config.TitleStyle.Render("Reply to " + m.replyTitle))
+		b.WriteString("\n")
+		if m.replyTo != nil {
+			b.WriteString(config.StatusStyle.Render(fmt.Sprintf("Replying to post #%d by %s", m.replyTo.PostNumber, m.replyTo.Username)))
+		} else {
+			b.WriteString(config.StatusStyle.Render("Replying to the topic"))
+		}
+		b.WriteString("\n\n")
+	} else {
+		heading := "Create New Topic"
+		if m.editTopicID != 0 {
+			heading = "Edit Topic"
+		}
+		b.WriteString(config.TitleStyle.Render(heading))
+		b.WriteString("\n\n")
+		b.WriteString(m.titleInput.View())
+		b.WriteString("\n\n")
+	}
+	// Editing only changes the title, category and tags
+	if m.editTopicID == 0 {
+		if m.preview {
+			b.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, m.contentInput.View(), " ", m.previewView()))
+		} else {
+			b.WriteString(m.contentInput.View())
+		}
+		b.WriteString("\n\n")
+	}
+	if m.replyTopicID == 0 {
+		b.WriteString(m.categoryInput.View())
+		b.WriteString("\n\n")
+		b.WriteString(m.tagsInput.View())
+		b.WriteString("\n\n")
+	}
 
-	if m.submitting {
+	if m.confirmDiscard {
+		b.WriteString(config.ErrorStyle.Render("Discard the draft? y/n"))
+	} else if m.submitting {
 		b.WriteString(config.StatusStyle.Render(m.message))
 	} else if m.err != nil {
-		b.WriteString(config.ErrorStyle.Render(m.err.Error()))
+		b.WriteString(config.ErrorStyle.Render(discourse.ErrorMessage(m.err)))
 	} else if m.message != "" {
 		b.WriteString(config.StatusStyle.Render(m.message))
 	}
 
-	help := "Tab/Shift+Tab: navigate | Ctrl+S: submit | Esc: cancel"
+	help := "Tab/Shift+Tab: navigate | Ctrl+P: toggle preview | Ctrl+S: submit and close | Ctrl+O: submit and start another | Esc: cancel"
+	if m.replyTopicID != 0 {
+		help = "Ctrl+P: toggle preview | Ctrl+S: post reply | Esc: cancel"
+	}
+	if m.editTopicID != 0 {
+		help = "Tab/Shift+Tab: navigate | Ctrl+S: save | Esc: cancel"
+	}
 	b.WriteString("\n\n" + help)
 
 	return b.String()
@@ -293,7 +902,7 @@ type Model struct {
 	Client             *discourse.Client
 	Topics             []discourse.Topic
 	Ready              bool
-	Fullscreen         bool
+	Layout             string
 	Search             textinput.Model
 	Searching          bool
 	LastRefresh        time.Time
@@ -307,34 +916,97 @@ type Model struct {
 	MoreTopicsURL      string
 	isLoadingMore      bool
 	isLoadingAll       bool
+	currentView        string
+	savedViews         map[string]topicView
+	// topicOffsets remembers how far each topic read this session was
+	// scrolled; restoreYOffset is the offset waiting for its posts to load.
+	topicOffsets   map[int]int
+	restoreYOffset int
+	categoryName   string
+	// categoryDescription is the filtered category's about text, shown
+	// above its topics; categoryExpanded shows it in full.
+	categoryDescription string
+	categoryExpanded    bool
+	CurrentUser         *discourse.UserProfile
+	PrefetchCount       int
+	RefreshInterval     time.Duration
+	prefetchCancel      context.CancelFunc
+	loadAllCancel       context.CancelFunc
+	postsCancel         context.CancelFunc
+	postsStreaming      bool
+	postsWindow         bool
+	failedTopicID       int
+	coolingDown         bool
+	cooldownTicking     bool
+	rateLimitTicking    bool
+	Colors              config.ColorConfig
+	ColorsPath          string
+	SettingsPath        string
+	ThemeEditor         themeEditorModel
+	Overlay             overlayModel
+	Links               linksModel
+	Categories          categoryPickerModel
+	Activity            activityModel
+	Chat                chatModel
+	Notifications       notificationsModel
+	ChatEnabled         bool
+	chatUnavailable     bool
+	confirmingQuit      bool
+	pendingG            bool
+	fullSearch          bool
+	profile             *discourse.UserProfile
+	jumpToPost          int
+	// readThrough is the furthest post of the open topic that has been on
+	// screen and markedThrough the furthest already reported as read.
+	// readPaused stops both after the topic was marked unread.
+	readThrough     int
+	markedThrough   int
+	readPaused      bool
+	topicDetail     *discourse.Topic
+	startTopicID    int
+	currentTopicID  int
+	currentPosts    []discourse.Post
+	postCursor      int
+	postOffsets     []int
+	rawPosts        map[int]bool
+	postCache       map[int]renderedPost
+	bookmarkPost    *discourse.Post
+	reminderInput   textinput.Model
+	CanCreateTopic  bool
+	Debug           bool
+	refreshGen      int
+	refreshDeferred bool
+	networkFailures int
+	// LastVisit is when the previous session started; topics bumped since
+	// then are announced until a key is pressed.
+	LastVisit time.Time
+	visitSeen bool
 }
 
 func InitialModel(client *discourse.Client, topics []discourse.Topic) Model {
+	orderLatestTopics(topics)
 	items := make([]list.Item, len(topics))
 	for i, topic := range topics {
 		items[i] = topicItem{topic: topic}
 	}
 
-	delegate := list.NewDefaultDelegate()
-	delegate.Styles.SelectedTitle = config.SelectedItemStyle
-	delegate.Styles.SelectedDesc = config.SelectedItemStyle
-	delegate.Styles.NormalTitle = config.ItemStyle
-	delegate.Styles.NormalDesc = config.ItemStyle
-	delegate.SetHeight(2)
-
-	l := list.New(items, delegate, 0, 0)
+	l := list.New(items, newTopicDelegate(), 0, 0)
 	l.Title = "Latest Topics"
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(true)
-	l.Styles.Title = config.TitleStyle
-	l.Styles.FilterPrompt = config.StatusStyle
-	l.Styles.FilterCursor = config.StatusStyle.Copy().Foreground(lipgloss.Color("170"))
+	applyListStyles(&l)
 	l.SetShowHelp(true)
+	// g and G jump within the open topic instead
+	l.KeyMap.GoToStart = key.NewBinding(key.WithKeys("home"), key.WithHelp("home", "go to start"))
+	l.KeyMap.GoToEnd = key.NewBinding(key.WithKeys("end"), key.WithHelp("end", "go to end"))
 
 	vp := viewport.New(0, 0)
 	vp.Style = lipgloss.NewStyle().
 		BorderStyle(lipgloss.RoundedBorder()).
 		BorderForeground(lipgloss.Color("62"))
+	// Plain d and u are taken by other commands
+	vp.KeyMap.HalfPageDown = key.NewBinding(key.WithKeys("ctrl+d"), key.WithHelp("ctrl+d", "½ page down"))
+	vp.KeyMap.HalfPageUp = key.NewBinding(key.WithKeys("ctrl+u"), key.WithHelp("ctrl+u", "½ page up"))
 
 	search := textinput.New()
 	search.Placeholder = "Search topics..."
@@ -343,54 +1015,805 @@ func InitialModel(client *discourse.Client, topics []discourse.Topic) Model {
 	instanceURL := strings.TrimPrefix(strings.TrimPrefix(client.BaseURL(), "https://"), "http://")
 
 	return Model{
-		List:        l,
-		Viewport:    vp,
-		Client:      client,
-		Topics:      topics,
-		Search:      search,
-		LastRefresh: time.Now(),
-		InstanceURL: instanceURL,
-		State:       stateTopicList,
+		List:            l,
+		Viewport:        vp,
+		Client:          client,
+		Topics:          topics,
+		Search:          search,
+		LastRefresh:     time.Now(),
+		InstanceURL:     instanceURL,
+		State:           stateTopicList,
+		Layout:          config.Current.Layout,
+		RefreshInterval: config.Current.RefreshInterval,
+		currentView:     viewLatest,
+		savedViews:      make(map[string]topicView),
+		topicOffsets:    make(map[int]int),
 	}
 }
 
-func (m Model) Init() tea.Cmd {
-	log.Printf("Initializing model with %d topics", len(m.Topics))
-	return tea.Tick(5*time.Minute, func(t time.Time) tea.Msg {
-		return refreshMsg{}
-	})
+// topicDelegate renders items like list.DefaultDelegate, but tells topics
+// the width they have so long titles are shortened before their category
+// and tags.
+type topicDelegate struct {
+	list.DefaultDelegate
 }
 
-type refreshMsg struct{}
+func (d topicDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if topic, ok := item.(topicItem); ok {
+		frame := max(d.Styles.NormalTitle.GetHorizontalFrameSize(), d.Styles.SelectedTitle.GetHorizontalFrameSize())
+		topic.width = m.Width() - frame
+		item = topic
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
 
-func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
-	var cmds []tea.Cmd
-	var cmd tea.Cmd
+func newTopicDelegate() topicDelegate {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = config.SelectedItemStyle
+	delegate.Styles.SelectedDesc = config.SelectedItemStyle
+	delegate.Styles.NormalTitle = config.ItemStyle
+	delegate.Styles.NormalDesc = config.ItemStyle
+	delegate.SetHeight(2)
+	return topicDelegate{delegate}
+}
 
-	m.StatusMessage = ""
+// applyListStyles copies the current config styles into the list, which keeps
+// its own copies; call it again after config.UpdateStyles.
+func applyListStyles(l *list.Model) {
+	l.Styles.Title = config.TitleStyle
+	l.Styles.FilterPrompt = config.StatusStyle
+	l.Styles.FilterCursor = config.StatusStyle.Copy().Foreground(lipgloss.Color("170"))
+}
 
-	switch m.State {
-	case stateNewTopic:
-		switch msg := msg.(type) {
-		case tea.KeyMsg:
-			if msg.Type == tea.KeyEsc {
-				m.State = stateTopicList
-				m.NewTopicForm.message = ""
-				m.NewTopicForm.err = nil
-				return m, nil
-			}
-		case topicCreatedMsg:
-			m.State = stateTopicList
-			m.StatusMessage = msg.message
-			m.NewTopicForm.submitting = false
-			m.NewTopicForm.message = ""
-			cmds = append(cmds, func() tea.Msg { return refreshMsg{} })
-			return m, tea.Batch(cmds...)
-		case topicCreateErrorMsg:
-			m.NewTopicForm.err = msg.err
-			m.NewTopicForm.submitting = false
-			m.NewTopicForm.message = ""
-			log.Printf("Error creating topic: %v", msg.err)
+func (m *Model) setListTopics(topics []discourse.Topic) {
+	if m.currentView == viewLatest {
+		orderLatestTopics(topics)
+	}
+	items := make([]list.Item, len(topics))
+	for i, topic := range topics {
+		items[i] = topicItem{topic: topic}
+	}
+	m.List.SetItems(items)
+}
+
+// appendNewTopics appends the topics of more that aren't in topics yet and
+// reports how many there were.
+func appendNewTopics(topics, more []discourse.Topic) ([]discourse.Topic, int) {
+	seen := make(map[int]bool, len(topics))
+	for _, topic := range topics {
+		seen[topic.ID] = true
+	}
+	added := 0
+	for _, topic := range more {
+		if seen[topic.ID] {
+			continue
+		}
+		seen[topic.ID] = true
+		topics = append(topics, topic)
+		added++
+	}
+	return topics, added
+}
+
+// orderLatestTopics sorts topics in place like Discourse's latest page:
+// globally pinned topics first, then category pins, then the rest in bump
+// order. With pin_to_top off the list is strictly by bump time.
+func orderLatestTopics(topics []discourse.Topic) {
+	rank := func(t discourse.Topic) int {
+		if !config.Current.PinToTop || !t.IsPinned() {
+			return 2
+		}
+		if t.PinnedGlobally {
+			return 0
+		}
+		return 1
+	}
+	sort.SliceStable(topics, func(i, j int) bool {
+		ri, rj := rank(topics[i]), rank(topics[j])
+		if ri != rj {
+			return ri < rj
+		}
+		if ri == 2 && !config.Current.PinToTop {
+			return topics[i].BumpedAt.After(topics[j].BumpedAt)
+		}
+		return false
+	})
+}
+
+// switchView stores the current view and shows the given topics under a new
+// view name. If the view was visited before, its saved state wins.
+func (m *Model) switchView(name string, topics []discourse.Topic, moreTopicsURL string) {
+	if name == m.currentView {
+		return
+	}
+	m.saveTopicOffset()
+	m.savedViews[m.currentView] = topicView{
+		topics:        m.Topics,
+		moreTopicsURL: m.MoreTopicsURL,
+		selected:      m.List.Index(),
+	}
+
+	selected := 0
+	if saved, ok := m.savedViews[name]; ok && topics == nil {
+		topics = saved.topics
+		moreTopicsURL = saved.moreTopicsURL
+		selected = saved.selected
+	}
+	delete(m.savedViews, name)
+
+	m.currentView = name
+	m.Topics = topics
+	m.MoreTopicsURL = moreTopicsURL
+	m.setListTopics(topics)
+	m.List.Select(selected)
+
+	m.List.SetStatusBarItemName("item", "items")
+	switch name {
+	case viewSearch:
+		m.List.Title = "Search Results"
+	case viewBookmarks:
+		m.List.Title = "Bookmarks"
+		m.List.SetStatusBarItemName("bookmark", "bookmarks")
+	case viewCategory:
+		m.List.Title = "Category: " + m.categoryName
+	case viewHot:
+		m.List.Title = "Hot Topics"
+	case viewTop:
+		m.List.Title = "Top Topics"
+	case viewNew:
+		m.List.Title = "New Topics"
+	case viewUnread:
+		m.List.Title = "Unread Topics"
+	default:
+		m.List.Title = "Latest Topics"
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	log.Printf("Initializing model with %d topics", len(m.Topics))
+	var cmds []tea.Cmd
+	// main may already have fetched the user while checking the session
+	if m.CurrentUser == nil {
+		cmds = append(cmds, m.loadCurrentUser())
+	}
+	if m.RefreshInterval > 0 {
+		cmds = append(cmds, tea.Tick(m.RefreshInterval, func(t time.Time) tea.Msg {
+			return refreshMsg{}
+		}))
+	}
+	if m.startTopicID != 0 {
+		topicID := m.startTopicID
+		cmds = append(cmds, func() tea.Msg { return openTopicMsg{topicID: topicID} })
+	}
+	return tea.Batch(cmds...)
+}
+
+// resumePosition makes a topic being opened from the list come back where
+// it was last scrolled to this session, or else at its first unread post.
+func (m *Model) resumePosition(topic discourse.Topic) {
+	if offset, ok := m.topicOffsets[topic.ID]; ok {
+		m.restoreYOffset = offset
+		return
+	}
+	if m.jumpToPost == 0 && topic.HasUnread() {
+		m.jumpToPost = topic.LastReadPostNumber + 1
+	}
+}
+
+// ContinueReading selects the first topic in the list with unread posts and
+// opens it at the first of them once the program starts. It reports false,
+// leaving the list as it is, when nothing is unread.
+func (m *Model) ContinueReading() bool {
+	for i, item := range m.List.Items() {
+		topic := item.(topicItem).topic
+		if !topic.HasUnread() {
+			continue
+		}
+		m.List.Select(i)
+		m.startTopicID = topic.ID
+		m.jumpToPost = topic.LastReadPostNumber + 1
+		return true
+	}
+	m.StatusMessage = "No unread topics"
+	return false
+}
+
+func (m Model) loadCurrentUser() tea.Cmd {
+	client := m.Client
+	return func() tea.Msg {
+		user, err := client.GetCurrentUser()
+		if err != nil {
+			log.Printf("Could not fetch current user: %v", err)
+			return nil
+		}
+		return currentUserLoadedMsg{user: user}
+	}
+}
+
+// switchAccount moves to the next account saved for this instance.
+func (m *Model) switchAccount() tea.Cmd {
+	accounts, err := config.ListAccounts(m.Client.BaseURL())
+	if err != nil {
+		m.StatusMessage = err.Error()
+		return nil
+	}
+	if len(accounts) < 2 {
+		m.StatusMessage = "No other account is saved for this forum; add one with --add-account"
+		return nil
+	}
+	next := accounts[0]
+	if m.CurrentUser != nil {
+		for i, account := range accounts {
+			if strings.EqualFold(account, m.CurrentUser.Username) {
+				next = accounts[(i+1)%len(accounts)]
+				break
+			}
+		}
+	}
+	if err := m.Client.SwitchCookies(config.AccountCookiesPath(m.Client.BaseURL(), next)); err != nil {
+		m.StatusMessage = fmt.Sprintf("Error switching to %s: %v", next, err)
+		log.Printf("Failed to switch account: %v", err)
+		return nil
+	}
+	log.Printf("Switched to account %s", next)
+	m.CurrentUser = nil
+	cmd := m.refreshTopics()
+	m.StatusMessage = fmt.Sprintf("Switched to @%s, refreshing...", next)
+	return tea.Batch(m.loadCurrentUser(), cmd)
+}
+
+// updateTopic applies fn to the topic with the given ID, both in m.Topics and
+// in the list items currently shown.
+func (m *Model) updateTopic(topicID int, fn func(*discourse.Topic)) {
+	if m.topicDetail != nil && m.topicDetail.ID == topicID {
+		fn(m.topicDetail)
+	}
+	for i := range m.Topics {
+		if m.Topics[i].ID == topicID {
+			fn(&m.Topics[i])
+		}
+	}
+	for i, item := range m.List.Items() {
+		if ti, ok := item.(topicItem); ok && ti.topic.ID == topicID {
+			fn(&ti.topic)
+			m.List.SetItem(i, ti)
+		}
+	}
+}
+
+// startPrefetch warms the post cache for the first PrefetchCount topics in the
+// list, replacing any prefetch already running.
+func (m *Model) startPrefetch() tea.Cmd {
+	if m.PrefetchCount <= 0 {
+		return nil
+	}
+	m.cancelPrefetch()
+
+	var topics []discourse.Topic
+	for _, item := range m.List.VisibleItems() {
+		if len(topics) >= m.PrefetchCount {
+			break
+		}
+		if ti, ok := item.(topicItem); ok {
+			topics = append(topics, ti.topic)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.prefetchCancel = cancel
+	client := m.Client
+	return func() tea.Msg {
+		return prefetchDoneMsg{fetched: client.PrefetchTopicPosts(ctx, topics)}
+	}
+}
+
+// watchCooldown starts polling the client's cooldown state for as long as a
+// bulk fetch is running.
+func (m *Model) watchCooldown() tea.Cmd {
+	if m.cooldownTicking {
+		return nil
+	}
+	m.cooldownTicking = true
+	return tea.Tick(200*time.Millisecond, func(time.Time) tea.Msg {
+		return cooldownTickMsg{}
+	})
+}
+
+// watchRateLimit ticks once a second while the client has background
+// requests paused, so the header countdown stays current.
+func (m *Model) watchRateLimit() tea.Cmd {
+	if m.rateLimitTicking || m.Client.RateLimitedFor() == 0 {
+		return nil
+	}
+	m.rateLimitTicking = true
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return rateLimitTickMsg{}
+	})
+}
+
+// firstPagePosts is how many posts come with a topic's first page. Jumps
+// further in first load jumpWindowBefore posts ahead of the target and
+// jumpWindowAfter following it.
+const (
+	firstPagePosts   = 20
+	jumpWindowBefore = 5
+	jumpWindowAfter  = 20
+)
+
+// openTopic loads a topic's posts into the viewport: cached posts, the posts
+// around jumpToPost or the first page straight away, then the whole topic in
+// the background.
+func (m *Model) openTopic(topicID int) tea.Cmd {
+	markRead := m.leaveTopic()
+	m.isLoadingPosts = true
+	if len(m.currentPosts) == 0 {
+		m.Viewport.SetContent("Loading posts...")
+	}
+	if m.topicDetail != nil && m.topicDetail.ID != topicID {
+		m.topicDetail = nil
+	}
+	m.readThrough, m.markedThrough, m.readPaused = 0, 0, false
+	m.currentTopicID = topicID
+	m.postsStreaming = false
+	m.postsWindow = false
+	m.restoreYOffset = 0
+	if m.postsCancel != nil {
+		m.postsCancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.postsCancel = cancel
+	client := m.Client
+	jump := m.jumpToPost
+	quick := func() tea.Msg {
+		if cached, err := client.CachedTopicPosts(topicID); err == nil {
+			return postsLoadedMsg{topicID: topicID, posts: cached}
+		}
+		// Past the first page, start with the posts around the target so
+		// a deep link doesn't wait for the topic to stream in up to it
+		if jump > firstPagePosts {
+			around, err := client.GetTopicPostsAround(topicID, jump, jumpWindowBefore, jumpWindowAfter)
+			if err != nil {
+				return postsLoadErrorMsg{topicID: topicID, err: err}
+			}
+			return postsLoadedMsg{topicID: topicID, posts: around, window: true}
+		}
+		postsPage, err := client.GetTopicPostsPage(topicID, 1)
+		if err != nil {
+			return postsLoadErrorMsg{topicID: topicID, err: err}
+		}
+		return postsLoadedMsg{topicID: topicID, posts: postsPage}
+	}
+	// The full fetch sends every chunk it gets down msgs; each message but
+	// the last carries next, so a receive is waiting for it. Once ctx is
+	// cancelled both sides give up instead of waiting on each other.
+	msgs := make(chan tea.Msg)
+	next := func() tea.Msg {
+		select {
+		case msg := <-msgs:
+			return msg
+		case <-ctx.Done():
+			return postsLoadErrorMsg{topicID: topicID, err: ctx.Err()}
+		}
+	}
+	send := func(msg tea.Msg) {
+		select {
+		case msgs <- msg:
+		case <-ctx.Done():
+		}
+	}
+	full := func() tea.Msg {
+		go func() {
+			var posts []discourse.Post
+			topic, fullPosts, err := client.GetTopicChunkedContext(ctx, topicID, 0, func(chunk []discourse.Post) {
+				posts = append(posts, chunk...)
+				response := &discourse.TopicResponse{}
+				response.PostStream.Posts = slices.Clip(posts)
+				send(postsLoadedMsg{topicID: topicID, posts: response, next: next})
+			})
+			if err != nil {
+				send(postsLoadErrorMsg{topicID: topicID, err: err})
+				return
+			}
+			send(postsLoadedMsg{topicID: topicID, posts: fullPosts, topic: topic, full: true})
+		}()
+		return next()
+	}
+	return tea.Batch(quick, full, m.watchCooldown(), markRead)
+}
+
+// saveTopicOffset remembers how far the open topic is scrolled, for
+// resumePosition. Nothing is saved while its posts are still coming in.
+func (m *Model) saveTopicOffset() {
+	if m.currentTopicID == 0 || m.isLoadingPosts || m.restoreYOffset > 0 {
+		return
+	}
+	m.topicOffsets[m.currentTopicID] = m.Viewport.YOffset
+}
+
+// leaveTopic is called whenever the open topic is put away: it saves the
+// scroll position and reports what was read.
+func (m *Model) leaveTopic() tea.Cmd {
+	m.saveTopicOffset()
+	return m.flushRead()
+}
+
+// quit reports the posts read in the open topic before quitting, as
+// nothing else would for the last topic of a session.
+func (m *Model) quit() tea.Cmd {
+	return tea.Sequence(m.flushRead(), tea.Quit)
+}
+
+// cancelBulkFetch aborts a running load-all or full topic fetch and reports
+// whether there was one.
+func (m *Model) cancelBulkFetch() bool {
+	cancelled := false
+	if m.loadAllCancel != nil {
+		m.loadAllCancel()
+		m.loadAllCancel = nil
+		cancelled = true
+	}
+	if m.postsCancel != nil {
+		m.postsCancel()
+		m.postsCancel = nil
+		cancelled = true
+	}
+	return cancelled
+}
+
+func (m *Model) cancelPrefetch() {
+	if m.prefetchCancel != nil {
+		m.prefetchCancel()
+		m.prefetchCancel = nil
+	}
+}
+
+func (m Model) isModerator() bool {
+	return m.CurrentUser != nil && (m.CurrentUser.Moderator || m.CurrentUser.Admin)
+}
+
+// toggleTopicStatus flips the closed or archived flag of the selected topic.
+func (m Model) toggleTopicStatus(status string) (Model, tea.Cmd) {
+	if !m.isModerator() {
+		m.StatusMessage = "Only moderators can close or archive topics"
+		return m, nil
+	}
+	i, ok := m.List.SelectedItem().(topicItem)
+	if !ok {
+		return m, nil
+	}
+	topicID := i.topic.ID
+	enabled := !i.topic.Closed
+	if status == "archived" {
+		enabled = !i.topic.Archived
+	}
+	m.StatusMessage = fmt.Sprintf("Updating topic %s status...", status)
+	return m, func() tea.Msg {
+		var err error
+		if status == "archived" {
+			err = m.Client.SetTopicArchived(topicID, enabled)
+		} else {
+			err = m.Client.SetTopicClosed(topicID, enabled)
+		}
+		if err != nil {
+			return topicStatusErrorMsg{err: err}
+		}
+		return topicStatusUpdatedMsg{topicID: topicID, status: status, enabled: enabled}
+	}
+}
+
+// refreshMsg triggers an automatic refresh. Only the most recently scheduled
+// one (matching Model.refreshGen) is acted on, so manual refreshes don't
+// leave extra timers running.
+type refreshMsg struct{ gen int }
+
+// maxRefreshInterval caps the backoff after network failures, unless the
+// refresh interval itself is longer.
+const maxRefreshInterval = 30 * time.Minute
+
+// scheduleRefresh starts the timer for the next automatic refresh, doubling
+// the interval for every consecutive network failure. It does nothing when
+// automatic refresh is off.
+func (m *Model) scheduleRefresh() tea.Cmd {
+	m.refreshGen++
+	if m.RefreshInterval <= 0 {
+		return nil
+	}
+	gen := m.refreshGen
+	limit := max(m.RefreshInterval, maxRefreshInterval)
+	interval := m.RefreshInterval
+	for i := 0; i < m.networkFailures && interval < limit; i++ {
+		interval *= 2
+	}
+	interval = min(interval, limit)
+	return tea.Tick(interval, func(t time.Time) tea.Msg {
+		return refreshMsg{gen: gen}
+	})
+}
+
+// isReading reports whether the user is focused on something other than the
+// topic list: composing, in an overlay or editor, or reading fullscreen.
+func (m Model) isReading() bool {
+	return m.State != stateTopicList || m.Layout == config.LayoutViewport
+}
+
+// resumeRefresh runs an automatic refresh that was held back while reading,
+// once the user is back on the list.
+func (m *Model) resumeRefresh() tea.Cmd {
+	if !m.refreshDeferred || m.isReading() || m.isRefreshingTopics {
+		return nil
+	}
+	return m.refreshTopics()
+}
+
+func (m *Model) refreshTopics() tea.Cmd {
+	m.refreshDeferred = false
+	m.isRefreshingTopics = true
+	m.StatusMessage = "Refreshing topics..."
+	return func() tea.Msg {
+		response, err := m.Client.RefreshTopics()
+		if err != nil {
+			return topicsRefreshErrorMsg{err: err}
+		}
+		m.Client.EnrichTopicCategories(response.TopicList.Topics)
+		return topicsRefreshedMsg{response: response}
+	}
+}
+
+// topicsRefreshed shows the refreshed latest topics and schedules the next
+// refresh.
+func (m *Model) topicsRefreshed(msg topicsRefreshedMsg) tea.Cmd {
+	m.isRefreshingTopics = false
+	m.StatusMessage = "Topics refreshed!"
+	m.noteNetworkResult(nil)
+	m.CanCreateTopic = msg.response.TopicList.CanCreateTopic
+	if m.currentView == viewLatest {
+		m.setListTopics(msg.response.TopicList.Topics)
+		m.Topics = msg.response.TopicList.Topics
+		m.MoreTopicsURL = msg.response.TopicList.MoreTopicsURL
+	} else {
+		// Don't replace whatever is on screen; latest picks this up when switched back to.
+		m.savedViews[viewLatest] = topicView{
+			topics:        msg.response.TopicList.Topics,
+			moreTopicsURL: msg.response.TopicList.MoreTopicsURL,
+		}
+	}
+	m.LastRefresh = time.Now()
+	return tea.Batch(m.startPrefetch(), m.scheduleRefresh())
+}
+
+func (m *Model) topicsRefreshError(msg topicsRefreshErrorMsg) tea.Cmd {
+	m.isRefreshingTopics = false
+	m.noteNetworkResult(msg.err)
+	m.StatusMessage = fmt.Sprintf("Error refreshing topics: %s", discourse.ErrorMessage(msg.err))
+	log.Printf("Failed to refresh topics: %v", msg.err)
+	return tea.Batch(m.watchRateLimit(), m.scheduleRefresh())
+}
+
+// noteNetworkResult tracks whether the forum is reachable: a network error
+// puts the client in the offline state, anything else ends it.
+func (m *Model) noteNetworkResult(err error) {
+	if discourse.IsNetworkError(err) {
+		m.networkFailures++
+		return
+	}
+	if m.networkFailures > 0 {
+		log.Printf("Connection restored after %d failed requests", m.networkFailures)
+		m.networkFailures = 0
+		m.StatusMessage = "Back online"
+	}
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmds []tea.Cmd
+	var cmd tea.Cmd
+
+	// Ticks are handled in any state, and before the status line is
+	// cleared, as they arrive every second or faster while a bulk fetch runs
+	// or the client is rate limited
+	if _, ok := msg.(cooldownTickMsg); ok {
+		m.cooldownTicking = false
+		if m.loadAllCancel == nil && m.postsCancel == nil {
+			m.coolingDown = false
+			return m, nil
+		}
+		m.coolingDown = m.Client.CoolingDown()
+		return m, m.watchCooldown()
+	}
+	if _, ok := msg.(rateLimitTickMsg); ok {
+		m.rateLimitTicking = false
+		return m, m.watchRateLimit()
+	}
+
+	m.StatusMessage = ""
+
+	if msg, ok := msg.(currentUserLoadedMsg); ok {
+		m.CurrentUser = msg.user
+		return m, nil
+	}
+
+	// Handled before the state switch so a tick arriving mid-compose isn't lost
+	if msg, ok := msg.(refreshMsg); ok {
+		if msg.gen != m.refreshGen || m.isRefreshingTopics {
+			return m, nil
+		}
+		if m.Client.RateLimitedFor() > 0 {
+			// Skip this round rather than add to the server's load
+			return m, tea.Batch(m.scheduleRefresh(), m.watchRateLimit())
+		}
+		if config.Current.DeferRefresh && m.isReading() {
+			m.refreshDeferred = true
+			return m, nil
+		}
+		return m, m.refreshTopics()
+	}
+
+	// Handled before the state switch: a chunk dropped while another view is
+	// open would leave the topic half loaded, and a dropped refresh, load-all
+	// or next-page result would leave isRefreshingTopics, isLoadingAll or
+	// isLoadingMore set for good
+	switch msg := msg.(type) {
+	case topicsRefreshedMsg:
+		return m, m.topicsRefreshed(msg)
+	case topicsRefreshErrorMsg:
+		return m, m.topicsRefreshError(msg)
+	case postsLoadedMsg:
+		return m, m.postsLoaded(msg)
+	case postsLoadErrorMsg:
+		return m, m.postsLoadError(msg)
+	case loadAllTopicsMsg:
+		m.isLoadingAll = false
+		m.loadAllCancel = nil
+		m.StatusMessage = fmt.Sprintf("Loaded all %d topics!", len(msg.response.TopicList.Topics))
+
+		// LoadAllTopics always pages through latest
+		if m.currentView != viewLatest {
+			m.savedViews[viewLatest] = topicView{
+				topics:        msg.response.TopicList.Topics,
+				moreTopicsURL: msg.response.TopicList.MoreTopicsURL,
+			}
+			return m, nil
+		}
+
+		// Replace with all topics
+		m.Topics = msg.response.TopicList.Topics
+		m.MoreTopicsURL = msg.response.TopicList.MoreTopicsURL
+		m.setListTopics(m.Topics)
+		return m, nil
+	case loadAllTopicsErrorMsg:
+		m.isLoadingAll = false
+		m.loadAllCancel = nil
+		if errors.Is(msg.err, context.Canceled) {
+			m.StatusMessage = "Stopped loading all topics"
+			return m, nil
+		}
+		m.StatusMessage = fmt.Sprintf("Error loading all topics: %s", discourse.ErrorMessage(msg.err))
+		log.Printf("Failed to load all topics: %v", msg.err)
+		return m, nil
+	case moreTopicsLoadedMsg:
+		m.isLoadingMore = false
+
+		// The view may have changed while the page was loading
+		if msg.view != m.currentView {
+			if saved, ok := m.savedViews[msg.view]; ok {
+				topics, added := appendNewTopics(saved.topics, msg.response.TopicList.Topics)
+				saved.topics = topics
+				saved.moreTopicsURL = msg.response.TopicList.MoreTopicsURL
+				m.savedViews[msg.view] = saved
+				m.StatusMessage = fmt.Sprintf("Loaded %d more topics!", added)
+			}
+			return m, nil
+		}
+
+		// Topics bumped since the last page was fetched show up again
+		topics, added := appendNewTopics(m.Topics, msg.response.TopicList.Topics)
+		m.StatusMessage = fmt.Sprintf("Loaded %d more topics!", added)
+		m.Topics = topics
+		m.MoreTopicsURL = msg.response.TopicList.MoreTopicsURL
+		m.setListTopics(m.Topics)
+		return m, nil
+	case moreTopicsLoadErrorMsg:
+		m.isLoadingMore = false
+		m.StatusMessage = fmt.Sprintf("Error loading more topics: %s", discourse.ErrorMessage(msg.err))
+		log.Printf("Failed to load more topics: %v", msg.err)
+		return m, nil
+	case topicMarkedReadMsg:
+		m.updateTopic(msg.topicID, func(t *discourse.Topic) {
+			t.LastReadPostNumber = max(t.LastReadPostNumber, msg.postNumber)
+			remaining := max(t.HighestPostNumber-t.LastReadPostNumber, 0)
+			t.UnreadPosts = remaining
+			t.Unread = remaining
+			if remaining == 0 {
+				t.NewPosts = 0
+			}
+		})
+		return m, nil
+	case topicMarkReadErrorMsg:
+		// Not worth interrupting reading for; the next refresh shows the
+		// forum's own counts
+		log.Printf("Failed to mark topic read: %v", msg.err)
+		return m, nil
+	}
+
+	switch m.State {
+	case stateNewTopic:
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			leave := msg.Type == tea.KeyEsc
+			if m.NewTopicForm.confirmDiscard {
+				m.NewTopicForm.confirmDiscard = false
+				leave = msg.String() == "y" || msg.String() == "Y"
+				if !leave {
+					return m, nil
+				}
+			} else if msg.Type == tea.KeyCtrlC {
+				return m, m.quit()
+			} else if leave && m.NewTopicForm.hasDraft() {
+				m.NewTopicForm.confirmDiscard = true
+				return m, nil
+			}
+			if leave {
+				m.State = stateTopicList
+				m.NewTopicForm.message = ""
+				m.NewTopicForm.err = nil
+				return m, m.resumeRefresh()
+			}
+		case tea.WindowSizeMsg:
+			m.Width = msg.Width
+			m.Height = msg.Height
+		case topicCreatedMsg:
+			m.NewTopicForm.submitting = false
+			m.NewTopicForm.message = ""
+			if m.NewTopicForm.keepOpen {
+				// Refresh once the composer is closed
+				m.refreshDeferred = true
+				m.NewTopicForm.clearForNext()
+				m.NewTopicForm.message = msg.message
+				cmds = append(cmds, textinput.Blink)
+				return m, tea.Batch(cmds...)
+			}
+			m.State = stateTopicList
+			m.StatusMessage = msg.message
+			if !m.isRefreshingTopics {
+				cmds = append(cmds, m.refreshTopics())
+			}
+			return m, tea.Batch(cmds...)
+		case topicUpdatedMsg:
+			m.State = stateTopicList
+			m.NewTopicForm.submitting = false
+			updated := []discourse.Topic{{ID: msg.topicID, CategoryID: msg.categoryID}}
+			m.Client.EnrichTopicCategories(updated)
+			m.updateTopic(msg.topicID, func(t *discourse.Topic) {
+				t.Title = msg.title
+				if msg.categoryID != 0 {
+					t.CategoryID = msg.categoryID
+					t.CategoryName = updated[0].CategoryName
+					t.CategoryColor = updated[0].CategoryColor
+				}
+				t.Tags = msg.tags
+			})
+			m.StatusMessage = "Topic updated"
+			return m, m.resumeRefresh()
+		case replyCreatedMsg:
+			m.State = stateTopicList
+			m.NewTopicForm.submitting = false
+			m.StatusMessage = msg.message
+			// Show the reply right away; the reload below brings in anything
+			// else posted meanwhile
+			if m.currentTopicID == m.NewTopicForm.replyTopicID && msg.post != nil {
+				m.currentPosts = append(m.currentPosts, *msg.post)
+				m.postCursor = len(m.currentPosts) - 1
+				m.renderPosts()
+				if len(m.postOffsets) > m.postCursor {
+					m.Viewport.SetYOffset(m.postOffsets[m.postCursor])
+				}
+				m.jumpToPost = msg.post.PostNumber
+			}
+			return m, m.openTopic(m.NewTopicForm.replyTopicID)
+		case topicCreateErrorMsg:
+			m.NewTopicForm.err = msg.err
+			m.NewTopicForm.submitting = false
+			m.NewTopicForm.message = ""
+			log.Printf("Error creating topic: %v", msg.err)
 			return m, nil
 		}
 		newForm, newCmd := m.NewTopicForm.Update(msg)
@@ -398,140 +1821,586 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, newCmd)
 		return m, tea.Batch(cmds...)
 
-	case stateTopicList:
+	case stateLinks:
+		if msg, ok := msg.(tea.KeyMsg); ok && m.Links.list.FilterState() != list.Filtering {
+			switch msg.String() {
+			case "esc", "q":
+				m.State = stateTopicList
+				return m, m.resumeRefresh()
+			case "ctrl+c":
+				return m, m.quit()
+			}
+		}
+		if msg, ok := msg.(tea.WindowSizeMsg); ok {
+			m.Width = msg.Width
+			m.Height = msg.Height
+		}
+		m.Links, cmd = m.Links.Update(msg)
+		return m, cmd
+
+	case stateCategories:
+		if msg, ok := msg.(tea.KeyMsg); ok && m.Categories.list.FilterState() != list.Filtering {
+			switch msg.String() {
+			case "esc", "q":
+				m.State = stateTopicList
+				return m, m.resumeRefresh()
+			case "ctrl+c":
+				return m, m.quit()
+			case "enter":
+				item, ok := m.Categories.list.SelectedItem().(categoryItem)
+				if !ok {
+					return m, nil
+				}
+				m.State = stateTopicList
+				return m, m.loadCategory(item.category.ID, item.category.Name, item.category.Slug)
+			}
+		}
+		if msg, ok := msg.(tea.WindowSizeMsg); ok {
+			m.Width = msg.Width
+			m.Height = msg.Height
+		}
+		m.Categories, cmd = m.Categories.Update(msg)
+		if m.Categories.sort != config.Current.CategorySort {
+			config.Current.CategorySort = m.Categories.sort
+			m.saveSettings()
+		}
+		return m, cmd
+
+	case stateChat:
+		if msg, ok := msg.(tea.KeyMsg); ok && !m.Chat.inChannel() && m.Chat.list.FilterState() != list.Filtering {
+			switch msg.String() {
+			case "esc", "q":
+				m.State = stateTopicList
+				return m, m.resumeRefresh()
+			}
+		}
+		if msg, ok := msg.(tea.KeyMsg); ok && msg.String() == "ctrl+c" {
+			return m, m.quit()
+		}
+		if msg, ok := msg.(tea.WindowSizeMsg); ok {
+			m.Width = msg.Width
+			m.Height = msg.Height
+		}
+		m.Chat, cmd = m.Chat.Update(msg)
+		return m, cmd
+
+	case stateActivity:
+		if msg, ok := msg.(tea.KeyMsg); ok && m.Activity.list.FilterState() != list.Filtering {
+			switch msg.String() {
+			case "esc", "q":
+				m.State = stateTopicList
+				return m, m.resumeRefresh()
+			case "ctrl+c":
+				return m, m.quit()
+			case "enter":
+				item, ok := m.Activity.list.SelectedItem().(activityItem)
+				if !ok {
+					return m, nil
+				}
+				m.State = stateTopicList
+				m.currentPosts = nil
+				m.postCursor = 0
+				m.jumpToPost = item.action.PostNumber
+				return m, tea.Batch(m.openTopic(item.action.TopicID), m.resumeRefresh())
+			}
+		}
+		if msg, ok := msg.(tea.WindowSizeMsg); ok {
+			m.Width = msg.Width
+			m.Height = msg.Height
+		}
+		m.Activity, cmd = m.Activity.Update(msg)
+		return m, cmd
+
+	case stateNotifications:
+		if msg, ok := msg.(tea.KeyMsg); ok && m.Notifications.list.FilterState() != list.Filtering {
+			switch msg.String() {
+			case "esc", "q":
+				m.State = stateTopicList
+				return m, m.resumeRefresh()
+			case "ctrl+c":
+				return m, m.quit()
+			case "enter":
+				item, ok := m.Notifications.list.SelectedItem().(notificationItem)
+				if !ok {
+					return m, nil
+				}
+				if item.notification.TopicID == 0 {
+					return m, nil
+				}
+				m.State = stateTopicList
+				m.currentPosts = nil
+				m.postCursor = 0
+				m.jumpToPost = item.notification.PostNumber
+				return m, tea.Batch(m.openTopic(item.notification.TopicID), m.resumeRefresh())
+			}
+		}
+		if msg, ok := msg.(tea.WindowSizeMsg); ok {
+			m.Width = msg.Width
+			m.Height = msg.Height
+		}
+		m.Notifications, cmd = m.Notifications.Update(msg)
+		return m, cmd
+
+	case stateOverlay:
 		switch msg := msg.(type) {
-		case refreshMsg:
-			if m.isRefreshingTopics {
+		case tea.KeyMsg:
+			switch msg.String() {
+			case "esc", "q":
+				m.State = stateTopicList
+				m.profile = nil
+				return m, m.resumeRefresh()
+			case "ctrl+c":
+				return m, m.quit()
+			case "f":
+				if m.profile == nil || !m.profile.CanFollow {
+					break
+				}
+				username := m.profile.Username
+				follow := !m.profile.IsFollowed
+				client := m.Client
+				return m, func() tea.Msg {
+					var err error
+					if follow {
+						err = client.FollowUser(username)
+					} else {
+						err = client.UnfollowUser(username)
+					}
+					if err != nil {
+						return userFollowErrorMsg{err: err}
+					}
+					return userFollowedMsg{username: username, follow: follow}
+				}
+			case "a":
+				if m.profile == nil {
+					break
+				}
+				username := m.profile.Username
+				client := m.Client
+				m.Overlay.keys = "Loading activity... | "
+				return m, func() tea.Msg {
+					actions, err := client.GetUserActivity(username)
+					if err != nil {
+						return userActivityLoadErrorMsg{err: err}
+					}
+					return userActivityLoadedMsg{username: username, actions: actions}
+				}
+			}
+		case userActivityLoadedMsg:
+			if len(msg.actions) == 0 {
+				m.Overlay.keys = msg.username + " has no recent activity | "
+				return m, nil
+			}
+			m.profile = nil
+			m.Activity = newActivityModel(msg.username, msg.actions, m.Width, m.Height)
+			m.State = stateActivity
+			return m, nil
+		case userActivityLoadErrorMsg:
+			m.Overlay.keys = discourse.ErrorMessage(msg.err) + " | "
+			log.Printf("Failed to load user activity: %v", msg.err)
+			return m, nil
+		case userFollowedMsg:
+			if m.profile == nil || m.profile.Username != msg.username {
 				return m, nil
 			}
-			m.isRefreshingTopics = true
-			m.StatusMessage = "Refreshing topics..."
-			cmds = append(cmds, func() tea.Msg {
-				response, err := m.Client.RefreshTopics()
+			m.profile.IsFollowed = msg.follow
+			if msg.follow {
+				m.profile.TotalFollowers++
+			} else {
+				m.profile.TotalFollowers = max(m.profile.TotalFollowers-1, 0)
+			}
+			m.Overlay.viewport.SetContent(formatUserProfile(m.profile))
+			m.Overlay.keys = profileKeys(m.profile)
+			return m, nil
+		case userFollowErrorMsg:
+			m.Overlay.keys = discourse.ErrorMessage(msg.err) + " | "
+			log.Printf("Failed to change following: %v", msg.err)
+			return m, nil
+		}
+		if msg, ok := msg.(tea.WindowSizeMsg); ok {
+			m.Width = msg.Width
+			m.Height = msg.Height
+		}
+		m.Overlay, cmd = m.Overlay.Update(msg)
+		return m, cmd
+
+	case stateThemeEditor:
+		switch msg := msg.(type) {
+		case tea.WindowSizeMsg:
+			m.Width = msg.Width
+			m.Height = msg.Height
+			return m, nil
+		case tea.KeyMsg:
+			switch msg.Type {
+			case tea.KeyEsc:
+				config.UpdateStyles(m.ThemeEditor.original)
+				m.List.SetDelegate(newTopicDelegate())
+				applyListStyles(&m.List)
+				m.State = stateTopicList
+				return m, m.resumeRefresh()
+			case tea.KeyEnter:
+				colors, err := m.ThemeEditor.colors()
 				if err != nil {
-					return topicsRefreshErrorMsg{err: err}
+					m.ThemeEditor.err = err
+					return m, nil
+				}
+				m.Colors = colors
+				m.State = stateTopicList
+				cmds = append(cmds, m.resumeRefresh())
+				if m.ColorsPath == "" {
+					m.StatusMessage = "Colors applied for this session"
+					return m, tea.Batch(cmds...)
+				}
+				if err := config.SaveColors(m.ColorsPath, colors); err != nil {
+					m.StatusMessage = fmt.Sprintf("Error saving colors: %v", err)
+					log.Printf("Failed to save colors: %v", err)
+					return m, tea.Batch(cmds...)
 				}
-				categories, catErr := m.Client.GetCategories()
-				if catErr != nil {
-					log.Printf("Warning: failed to fetch categories during refresh: %v", catErr)
+				m.StatusMessage = "Colors saved"
+				return m, tea.Batch(cmds...)
+			}
+		}
+		m.ThemeEditor, cmd = m.ThemeEditor.Update(msg)
+		m.List.SetDelegate(newTopicDelegate())
+		applyListStyles(&m.List)
+		return m, cmd
+
+	case stateTopicList:
+		switch msg := msg.(type) {
+		case searchResultsMsg:
+			m.StatusMessage = fmt.Sprintf("Found %d posts and %d topics", len(msg.response.Posts), len(msg.response.Topics))
+			// Convert search results to topics for display
+			var searchTopics []discourse.Topic
+			for _, post := range msg.response.Posts {
+				topic := discourse.Topic{
+					ID:           post.TopicID,
+					Title:        post.Title,
+					Slug:         post.TopicSlug,
+					PostsCount:   0, // We don't have this info from search
+					CreatedAt:    post.CreatedAt,
+					LastPostedAt: post.CreatedAt,
+				}
+				searchTopics = append(searchTopics, topic)
+			}
+			// Add topics from search results
+			for _, topic := range msg.response.Topics {
+				searchTopics = append(searchTopics, topic)
+			}
+			if m.currentView == viewSearch {
+				m.Topics = searchTopics
+				m.MoreTopicsURL = ""
+				m.setListTopics(searchTopics)
+				m.List.Select(0)
+			} else {
+				m.switchView(viewSearch, searchTopics, "")
+			}
+			return m, tea.Batch(cmds...)
+		case topicSearchResultsMsg:
+			topics := msg.response.TopicList.Topics
+			m.StatusMessage = fmt.Sprintf("Found %s", pluralize(len(topics), "topic", "topics"))
+			if msg.response.TopicList.MoreTopicsURL != "" {
+				m.StatusMessage += " (m for more)"
+			}
+			if m.currentView == viewSearch {
+				m.Topics = topics
+				m.MoreTopicsURL = msg.response.TopicList.MoreTopicsURL
+				m.setListTopics(topics)
+				m.List.Select(0)
+			} else {
+				m.switchView(viewSearch, topics, msg.response.TopicList.MoreTopicsURL)
+			}
+			return m, tea.Batch(cmds...)
+		case topicStatusUpdatedMsg:
+			m.updateTopic(msg.topicID, func(t *discourse.Topic) {
+				if msg.status == "archived" {
+					t.Archived = msg.enabled
 				} else {
-					categoryMap := make(map[int]struct {
-						Name  string
-						Color string
-					})
-					for _, category := range categories.CategoryList.Categories {
-						categoryMap[category.ID] = struct {
-							Name  string
-							Color string
-						}{
-							Name:  category.Name,
-							Color: category.Color,
-						}
-					}
-					for i := range response.TopicList.Topics {
-						if cat, ok := categoryMap[response.TopicList.Topics[i].CategoryID]; ok {
-							response.TopicList.Topics[i].CategoryName = cat.Name
-							response.TopicList.Topics[i].CategoryColor = cat.Color
-						}
-					}
+					t.Closed = msg.enabled
 				}
-				return topicsRefreshedMsg{response: response}
 			})
+			switch {
+			case msg.enabled:
+				m.StatusMessage = fmt.Sprintf("Topic %s", msg.status)
+			case msg.status == "archived":
+				m.StatusMessage = "Topic unarchived"
+			default:
+				m.StatusMessage = "Topic reopened"
+			}
 			return m, tea.Batch(cmds...)
-		case topicsRefreshedMsg:
-			m.isRefreshingTopics = false
-			m.StatusMessage = "Topics refreshed!"
-			items := make([]list.Item, len(msg.response.TopicList.Topics))
-			for i, topic := range msg.response.TopicList.Topics {
-				items[i] = topicItem{topic: topic}
-			}
-			m.List.SetItems(items)
-			m.Topics = msg.response.TopicList.Topics
-			m.MoreTopicsURL = msg.response.TopicList.MoreTopicsURL
-			m.LastRefresh = time.Now()
-			cmds = append(cmds, tea.Tick(5*time.Minute, func(t time.Time) tea.Msg {
-				return refreshMsg{}
-			}))
+		case userProfileLoadedMsg:
+			m.StatusMessage = ""
+			m.Overlay = newOverlayModel("Profile of "+msg.user.Username, formatUserProfile(msg.user), m.Width, m.Height)
+			m.Overlay.keys = profileKeys(msg.user)
+			m.profile = msg.user
+			m.State = stateOverlay
+			return m, nil
+		case userProfileLoadErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error loading profile: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to load user profile: %v", msg.err)
 			return m, tea.Batch(cmds...)
-		case topicsRefreshErrorMsg:
-			m.isRefreshingTopics = false
-			m.StatusMessage = fmt.Sprintf("Error refreshing topics: %v", msg.err)
-			log.Printf("Failed to refresh topics: %v", msg.err)
-			cmds = append(cmds, tea.Tick(5*time.Minute, func(t time.Time) tea.Msg {
-				return refreshMsg{}
-			}))
+		case openTopicMsg:
+			m.currentPosts = nil
+			m.postCursor = 0
+			return m, m.openTopic(msg.topicID)
+		case topicMarkedUnreadMsg:
+			if msg.topicID == m.currentTopicID {
+				// Don't report the posts on screen as read again
+				m.readPaused = true
+			}
+			m.updateTopic(msg.topicID, func(t *discourse.Topic) {
+				t.LastReadPostNumber = msg.postNumber - 1
+				if t.HighestPostNumber >= msg.postNumber {
+					t.UnreadPosts = t.HighestPostNumber - msg.postNumber + 1
+					t.Unread = t.UnreadPosts
+				}
+			})
+			m.StatusMessage = fmt.Sprintf("Marked unread from post #%d", msg.postNumber)
 			return m, tea.Batch(cmds...)
-		case moreTopicsLoadedMsg:
-			m.isLoadingMore = false
-			m.StatusMessage = fmt.Sprintf("Loaded %d more topics!", len(msg.response.TopicList.Topics))
-
-			// Append new topics to existing ones
-			m.Topics = append(m.Topics, msg.response.TopicList.Topics...)
-			m.MoreTopicsURL = msg.response.TopicList.MoreTopicsURL
-
-			// Update list items
-			items := make([]list.Item, len(m.Topics))
-			for i, topic := range m.Topics {
-				items[i] = topicItem{topic: topic}
+		case topicMarkUnreadErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error marking topic unread: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to mark topic unread: %v", msg.err)
+			return m, tea.Batch(cmds...)
+		case prefetchDoneMsg:
+			log.Printf("Prefetched posts for %d topics", msg.fetched)
+			return m, nil
+		case topicStatusErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error updating topic: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to update topic status: %v", msg.err)
+			return m, tea.Batch(cmds...)
+		case bookmarksLoadedMsg:
+			if len(msg.response.TopicList.Topics) == 0 {
+				m.StatusMessage = "You have no bookmarks yet"
+			} else {
+				m.StatusMessage = fmt.Sprintf("Loaded %d bookmarks", len(msg.response.TopicList.Topics))
+			}
+			if m.currentView == viewBookmarks {
+				m.Topics = msg.response.TopicList.Topics
+				m.MoreTopicsURL = ""
+				m.setListTopics(m.Topics)
+			} else {
+				m.switchView(viewBookmarks, msg.response.TopicList.Topics, "")
 			}
-			m.List.SetItems(items)
 			return m, tea.Batch(cmds...)
-		case moreTopicsLoadErrorMsg:
-			m.isLoadingMore = false
-			m.StatusMessage = fmt.Sprintf("Error loading more topics: %v", msg.err)
-			log.Printf("Failed to load more topics: %v", msg.err)
+		case hotTopicsLoadedMsg:
+			m.StatusMessage = fmt.Sprintf("Showing %d hot topics (H or esc to go back)", len(msg.response.TopicList.Topics))
+			if m.currentView == viewHot {
+				m.Topics = msg.response.TopicList.Topics
+				m.MoreTopicsURL = msg.response.TopicList.MoreTopicsURL
+				m.setListTopics(m.Topics)
+			} else {
+				m.switchView(viewHot, msg.response.TopicList.Topics, msg.response.TopicList.MoreTopicsURL)
+			}
 			return m, tea.Batch(cmds...)
-		case loadAllTopicsMsg:
-			m.isLoadingAll = false
-			m.StatusMessage = fmt.Sprintf("Loaded all %d topics!", len(msg.response.TopicList.Topics))
-
-			// Replace with all topics
-			m.Topics = msg.response.TopicList.Topics
-			m.MoreTopicsURL = msg.response.TopicList.MoreTopicsURL
-
-			// Update list items
-			items := make([]list.Item, len(m.Topics))
-			for i, topic := range m.Topics {
-				items[i] = topicItem{topic: topic}
+		case hotTopicsLoadErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error loading hot topics: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to load hot topics: %v", msg.err)
+			return m, tea.Batch(cmds...)
+		case sortedTopicsLoadedMsg:
+			m.StatusMessage = fmt.Sprintf("Showing %d %s topics (1 or esc to go back)", len(msg.response.TopicList.Topics), msg.sort)
+			if m.currentView == msg.sort {
+				m.Topics = msg.response.TopicList.Topics
+				m.MoreTopicsURL = msg.response.TopicList.MoreTopicsURL
+				m.setListTopics(m.Topics)
+			} else {
+				m.switchView(msg.sort, msg.response.TopicList.Topics, msg.response.TopicList.MoreTopicsURL)
 			}
-			m.List.SetItems(items)
 			return m, tea.Batch(cmds...)
-		case loadAllTopicsErrorMsg:
-			m.isLoadingAll = false
-			m.StatusMessage = fmt.Sprintf("Error loading all topics: %v", msg.err)
-			log.Printf("Failed to load all topics: %v", msg.err)
+		case sortedTopicsLoadErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error loading %s topics: %s", msg.sort, discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to load %s topics: %v", msg.sort, msg.err)
 			return m, tea.Batch(cmds...)
-		case searchResultsMsg:
-			m.StatusMessage = fmt.Sprintf("Found %d posts and %d topics", len(msg.response.Posts), len(msg.response.Topics))
-			// Convert search results to topics for display
-			var searchTopics []discourse.Topic
-			for _, post := range msg.response.Posts {
-				topic := discourse.Topic{
-					ID:          post.TopicID,
-					Title:       post.Title,
-					Slug:        post.TopicSlug,
-					PostsCount:  0, // We don't have this info from search
-					CreatedAt:   post.CreatedAt,
-					LastPostedAt: post.CreatedAt,
+		case categoryTopicsLoadedMsg:
+			m.StatusMessage = fmt.Sprintf("Showing %d topics in %s (esc to go back)", len(msg.response.TopicList.Topics), msg.name)
+			m.categoryName = msg.name
+			m.categoryDescription = msg.description
+			m.categoryExpanded = false
+			if m.currentView == viewCategory {
+				m.Topics = msg.response.TopicList.Topics
+				m.MoreTopicsURL = msg.response.TopicList.MoreTopicsURL
+				m.setListTopics(m.Topics)
+				m.List.Title = "Category: " + msg.name
+				m.List.Select(0)
+			} else {
+				m.switchView(viewCategory, msg.response.TopicList.Topics, msg.response.TopicList.MoreTopicsURL)
+			}
+			return m, tea.Batch(cmds...)
+		case topicBookmarkToggledMsg:
+			m.updateTopic(msg.topicID, func(t *discourse.Topic) {
+				t.Bookmarked = msg.bookmarked
+			})
+			if msg.bookmarked {
+				m.StatusMessage = "Bookmarked topic"
+			} else {
+				m.StatusMessage = "Removed topic bookmark"
+			}
+			return m, tea.Batch(cmds...)
+		case topicBookmarkErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error bookmarking topic: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to toggle topic bookmark: %v", msg.err)
+			return m, tea.Batch(cmds...)
+		case postLikeToggledMsg:
+			for i := range m.currentPosts {
+				post := &m.currentPosts[i]
+				if post.ID != msg.postID {
+					continue
 				}
-				searchTopics = append(searchTopics, topic)
+				if msg.updated != nil && len(msg.updated.ActionsSummary) > 0 {
+					post.ActionsSummary = msg.updated.ActionsSummary
+				} else {
+					setLiked(post, msg.liked)
+				}
+				delete(m.postCache, post.ID)
+				if msg.liked {
+					m.StatusMessage = fmt.Sprintf("Liked post #%d", post.PostNumber)
+				} else {
+					m.StatusMessage = fmt.Sprintf("Removed like from post #%d", post.PostNumber)
+				}
+				offset := m.Viewport.YOffset
+				m.renderPosts()
+				m.Viewport.SetYOffset(offset)
+				break
+			}
+			return m, tea.Batch(cmds...)
+		case postLikeErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error liking post: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to toggle like: %v", msg.err)
+			return m, tea.Batch(cmds...)
+		case postBookmarkedMsg:
+			if msg.reminderAt != nil {
+				m.StatusMessage = fmt.Sprintf("Bookmarked post #%d, reminder %s", msg.post.PostNumber, msg.reminderAt.Format("Mon Jan 2 15:04"))
+			} else {
+				m.StatusMessage = fmt.Sprintf("Bookmarked post #%d", msg.post.PostNumber)
+			}
+			return m, tea.Batch(cmds...)
+		case postBookmarkErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error bookmarking post: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to bookmark post: %v", msg.err)
+			return m, tea.Batch(cmds...)
+		case categoriesLoadedMsg:
+			m.StatusMessage = ""
+			if len(msg.categories) == 0 {
+				m.StatusMessage = "No categories to show"
+				return m, tea.Batch(cmds...)
+			}
+			m.Categories = newCategoryPickerModel(msg.categories, m.Width, m.Height)
+			m.State = stateCategories
+			return m, tea.Batch(cmds...)
+		case categoriesLoadErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error loading categories: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to load categories: %v", msg.err)
+			return m, tea.Batch(cmds...)
+		case chatChannelsLoadedMsg:
+			m.StatusMessage = ""
+			if len(msg.channels) == 0 {
+				m.StatusMessage = "No chat channels to show"
+				return m, tea.Batch(cmds...)
 			}
-			// Add topics from search results
-			for _, topic := range msg.response.Topics {
-				searchTopics = append(searchTopics, topic)
+			m.Chat = newChatModel(m.Client, msg.channels, m.Width, m.Height)
+			m.State = stateChat
+			return m, tea.Batch(cmds...)
+		case notificationsLoadedMsg:
+			m.StatusMessage = ""
+			if len(msg.notifications) == 0 {
+				m.StatusMessage = "No notifications"
+				return m, tea.Batch(cmds...)
 			}
-			items := make([]list.Item, len(searchTopics))
-			for i, topic := range searchTopics {
-				items[i] = topicItem{topic: topic}
+			m.Notifications = newNotificationsModel(msg.notifications, m.Width, m.Height)
+			m.State = stateNotifications
+			return m, tea.Batch(cmds...)
+		case notificationsLoadErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error loading notifications: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to load notifications: %v", msg.err)
+			return m, tea.Batch(cmds...)
+		case chatChannelsLoadErrorMsg:
+			if errors.Is(msg.err, discourse.ErrChatUnavailable) {
+				// Hide chat for the rest of the session
+				m.chatUnavailable = true
 			}
-			m.List.SetItems(items)
+			m.StatusMessage = fmt.Sprintf("Error loading chat: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to load chat channels: %v", msg.err)
+			return m, tea.Batch(cmds...)
+		case categoryTopicsLoadErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error loading category: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to load category topics: %v", msg.err)
+			return m, tea.Batch(cmds...)
+		case bookmarksLoadErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error loading bookmarks: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Failed to load bookmarks: %v", msg.err)
 			return m, tea.Batch(cmds...)
 		case searchErrorMsg:
-			m.StatusMessage = fmt.Sprintf("Search error: %v", msg.err)
+			m.StatusMessage = fmt.Sprintf("Search error: %s", discourse.ErrorMessage(msg.err))
 			log.Printf("Search failed: %v", msg.err)
 			return m, tea.Batch(cmds...)
 
 		case tea.KeyMsg:
+			// Prefetching is only worth the bandwidth while the user is idle
+			m.cancelPrefetch()
+			m.visitSeen = true
+
+			if m.confirmingQuit {
+				m.confirmingQuit = false
+				m.StatusMessage = ""
+				if msg.String() == "y" || msg.String() == "Y" {
+					return m, m.quit()
+				}
+				return m, nil
+			}
+
+			if m.pendingG {
+				m.pendingG = false
+				m.StatusMessage = ""
+				if msg.String() == "g" {
+					if len(m.currentPosts) > 0 {
+						m.postCursor = 0
+						m.renderPosts()
+					}
+					m.Viewport.GotoTop()
+					return m, nil
+				}
+				if msg.String() != "n" {
+					return m, nil
+				}
+				if m.CurrentUser == nil {
+					m.StatusMessage = "Notifications are only available when logged in"
+					return m, nil
+				}
+				m.StatusMessage = "Loading notifications..."
+				client := m.Client
+				cmds = append(cmds, func() tea.Msg {
+					notifications, err := client.GetNotifications()
+					if err != nil {
+						return notificationsLoadErrorMsg{err: err}
+					}
+					return notificationsLoadedMsg{notifications: notifications}
+				})
+				return m, tea.Batch(cmds...)
+			}
+
+			if m.bookmarkPost != nil {
+				switch msg.String() {
+				case "esc":
+					m.bookmarkPost = nil
+					return m, nil
+				case "enter":
+					reminderAt, err := parseReminder(m.reminderInput.Value(), time.Now())
+					if err != nil {
+						m.StatusMessage = err.Error()
+						return m, nil
+					}
+					post := *m.bookmarkPost
+					m.bookmarkPost = nil
+					m.StatusMessage = fmt.Sprintf("Bookmarking post #%d...", post.PostNumber)
+					cmds = append(cmds, func() tea.Msg {
+						if err := m.Client.BookmarkPost(post.ID, reminderAt); err != nil {
+							return postBookmarkErrorMsg{err: err}
+						}
+						return postBookmarkedMsg{post: post, reminderAt: reminderAt}
+					})
+					return m, tea.Batch(cmds...)
+				}
+				m.reminderInput, cmd = m.reminderInput.Update(msg)
+				return m, cmd
+			}
+
 			if m.Searching {
 				switch msg.String() {
 				case "esc":
@@ -547,6 +2416,25 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				case "enter":
 					query := m.Search.Value()
+					if m.fullSearch {
+						m.Searching = false
+						m.Search.Blur()
+						m.Search.Reset()
+						if query == "" {
+							m.switchView(viewLatest, nil, "")
+							return m, nil
+						}
+						m.StatusMessage = fmt.Sprintf("Searching the forum for '%s'...", query)
+						client := m.Client
+						cmds = append(cmds, func() tea.Msg {
+							response, err := client.SearchTopics(query)
+							if err != nil {
+								return searchErrorMsg{err: err}
+							}
+							return topicSearchResultsMsg{response: response}
+						})
+						return m, tea.Batch(cmds...)
+					}
 					if query != "" {
 						m.Searching = false
 						m.Search.Blur()
@@ -579,108 +2467,510 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				}
 			}
 
+			if m.List.FilterState() != list.Filtering && m.Layout != config.LayoutViewport {
+				if handled := m.pageList(msg.String()); handled {
+					return m, nil
+				}
+			}
+
 			switch msg.String() {
-			case "ctrl+c", "q":
-				return m, tea.Quit
+			case "ctrl+c":
+				return m, m.quit()
+			case "q":
+				if config.Current.ConfirmQuit {
+					m.confirmingQuit = true
+					m.StatusMessage = "Quit? y/n"
+					return m, nil
+				}
+				return m, m.quit()
 			case "n":
+				if !m.CanCreateTopic {
+					if m.CurrentUser == nil {
+						m.StatusMessage = "Log in to create topics"
+					} else {
+						m.StatusMessage = "You don't have permission to create topics on this forum"
+					}
+					return m, nil
+				}
 				m.State = stateNewTopic
 				m.NewTopicForm = InitialNewTopicModel(m.Client, m.Width, m.Height-4)
 				m.NewTopicForm.message = ""
 				m.NewTopicForm.err = nil
 				return m, m.NewTopicForm.Init()
 			case "f":
-				m.Fullscreen = !m.Fullscreen
-				if m.Fullscreen {
-					m.Viewport.Width = m.Width
-					m.Viewport.Height = m.Height
+				if m.Layout == config.LayoutViewport {
+					m.Layout = config.LayoutSplit
 				} else {
-					listHeight := m.Height / 2
-					m.List.SetWidth(m.Width)
-					m.List.SetHeight(listHeight)
-					m.Viewport.Width = m.Width
-					m.Viewport.Height = m.Height - listHeight - 1
+					m.Layout = config.LayoutViewport
+				}
+				m.resizeLayout()
+				return m, m.resumeRefresh()
+			case "ctrl+w":
+				switch m.Layout {
+				case config.LayoutSplit:
+					m.Layout = config.LayoutList
+				case config.LayoutList:
+					m.Layout = config.LayoutViewport
+				default:
+					m.Layout = config.LayoutSplit
+				}
+				m.resizeLayout()
+				m.saveLayout()
+				return m, m.resumeRefresh()
+			case "b":
+				i, ok := m.List.SelectedItem().(topicItem)
+				if !ok {
+					return m, nil
+				}
+				if m.CurrentUser == nil {
+					m.StatusMessage = "Bookmarks are only available when logged in"
+					return m, nil
+				}
+				client := m.Client
+				topicID, bookmark := i.topic.ID, !i.topic.Bookmarked
+				cmds = append(cmds, func() tea.Msg {
+					var err error
+					if bookmark {
+						err = client.BookmarkTopic(topicID)
+					} else {
+						err = client.RemoveBookmark(topicID)
+					}
+					if err != nil {
+						return topicBookmarkErrorMsg{err: err}
+					}
+					return topicBookmarkToggledMsg{topicID: topicID, bookmarked: bookmark}
+				})
+				return m, tea.Batch(cmds...)
+			case "B":
+				if m.CurrentUser == nil {
+					m.StatusMessage = "Bookmarks are only available when logged in"
+					return m, nil
+				}
+				m.StatusMessage = "Loading bookmarks..."
+				cmds = append(cmds, func() tea.Msg {
+					response, err := m.Client.GetBookmarks()
+					if err != nil {
+						return bookmarksLoadErrorMsg{err: err}
+					}
+					return bookmarksLoadedMsg{response: response}
+				})
+				return m, tea.Batch(cmds...)
+			case "1", "2", "3", "4":
+				if m.List.FilterState() == list.Filtering {
+					break
+				}
+				sort := sortKeys[msg.String()]
+				if sort == viewLatest {
+					m.switchView(viewLatest, nil, "")
+					return m, nil
+				}
+				if (sort == viewNew || sort == viewUnread) && m.CurrentUser == nil {
+					m.StatusMessage = "Log in to see " + sort + " topics"
+					return m, nil
+				}
+				m.StatusMessage = "Loading " + sort + " topics..."
+				client := m.Client
+				cmds = append(cmds, func() tea.Msg {
+					response, err := client.GetTopics(sort)
+					if err != nil {
+						return sortedTopicsLoadErrorMsg{sort: sort, err: err}
+					}
+					return sortedTopicsLoadedMsg{sort: sort, response: response}
+				})
+				return m, tea.Batch(cmds...)
+			case "H":
+				if m.currentView == viewHot {
+					m.switchView(viewLatest, nil, "")
+					return m, nil
+				}
+				m.StatusMessage = "Loading hot topics..."
+				client := m.Client
+				cmds = append(cmds, func() tea.Msg {
+					response, err := client.GetHotTopics()
+					if err != nil {
+						return hotTopicsLoadErrorMsg{err: err}
+					}
+					return hotTopicsLoadedMsg{response: response}
+				})
+				return m, tea.Batch(cmds...)
+			case "c":
+				i, ok := m.List.SelectedItem().(topicItem)
+				if !ok || m.List.FilterState() == list.Filtering {
+					return m, nil
+				}
+				if i.topic.CategoryID == 0 {
+					m.StatusMessage = "This topic has no category"
+					return m, nil
 				}
+				cmds = append(cmds, m.loadCategory(i.topic.CategoryID, i.topic.CategoryName, ""))
+				return m, tea.Batch(cmds...)
+			case "C":
+				m.StatusMessage = "Loading categories..."
+				client := m.Client
+				cmds = append(cmds, func() tea.Msg {
+					response, err := client.GetCategories()
+					if err != nil {
+						return categoriesLoadErrorMsg{err: err}
+					}
+					return categoriesLoadedMsg{categories: response.CategoryList.Categories}
+				})
+				return m, tea.Batch(cmds...)
+			case "g":
+				if m.List.FilterState() == list.Filtering {
+					break
+				}
+				m.pendingG = true
+				m.StatusMessage = "g-  (g: top of the topic, n: notifications)"
 				return m, nil
-			case "/":
-				m.Searching = !m.Searching
-				if m.Searching {
-					return m, m.Search.Focus()
+			case "G":
+				if m.List.FilterState() == list.Filtering {
+					break
 				}
+				if len(m.currentPosts) > 0 {
+					m.postCursor = len(m.currentPosts) - 1
+					m.renderPosts()
+				}
+				m.Viewport.GotoBottom()
 				return m, nil
-			case "R":
-				if m.isRefreshingTopics {
+			case "ctrl+t":
+				if !m.ChatEnabled || m.chatUnavailable {
+					return m, nil
+				}
+				m.StatusMessage = "Loading chat..."
+				client := m.Client
+				cmds = append(cmds, func() tea.Msg {
+					channels, err := client.GetChatChannels()
+					if err != nil {
+						return chatChannelsLoadErrorMsg{err: err}
+					}
+					return chatChannelsLoadedMsg{channels: channels}
+				})
+				return m, tea.Batch(cmds...)
+			case "o":
+				i, ok := m.List.SelectedItem().(topicItem)
+				if !ok || m.List.FilterState() == list.Filtering {
+					return m, nil
+				}
+				link := m.Client.TopicURL(i.topic)
+				if err := openURL(link); err != nil {
+					log.Printf("Opening topic in browser: %v", err)
+					m.StatusMessage = "No browser could be launched, topic link: " + link
+				} else {
+					m.StatusMessage = "Opened topic in browser"
+				}
+				return m, nil
+			case "y":
+				if m.List.FilterState() == list.Filtering {
+					return m, nil
+				}
+				i, ok := m.List.SelectedItem().(topicItem)
+				if !ok {
+					return m, nil
+				}
+				link, what := m.Client.TopicURL(i.topic), "topic"
+				// The posts shown may belong to a topic the cursor has left
+				if post, ok := m.focusedPost(); ok && i.topic.ID == m.currentTopicID {
+					link = m.Client.PostURL(post)
+					what = fmt.Sprintf("post #%d", post.PostNumber)
+				}
+				if err := copyToClipboard(link); err != nil {
+					log.Printf("Copying %s link: %v", what, err)
+					m.StatusMessage = fmt.Sprintf("No clipboard available, %s link: %s", what, link)
+				} else {
+					m.StatusMessage = "Copied link to " + what
+				}
+				return m, nil
+			case "Y":
+				i, ok := m.List.SelectedItem().(topicItem)
+				if !ok || m.List.FilterState() == list.Filtering {
+					return m, nil
+				}
+				link := m.Client.TopicURL(i.topic)
+				if err := copyToClipboard(link); err != nil {
+					log.Printf("Copying topic link: %v", err)
+					m.StatusMessage = "No clipboard available, topic link: " + link
+				} else {
+					m.StatusMessage = "Copied link to topic"
+				}
+				return m, nil
+			case "ctrl+y":
+				post, ok := m.focusedPost()
+				if !ok || m.List.FilterState() == list.Filtering {
+					return m, nil
+				}
+				if err := copyToClipboard(strings.TrimSpace(postText(post))); err != nil {
+					log.Printf("Copying post text: %v", err)
+					m.StatusMessage = "No clipboard available, couldn't copy the post's text"
+				} else {
+					m.StatusMessage = fmt.Sprintf("Copied text of post #%d by %s", post.PostNumber, post.Username)
+				}
+				return m, nil
+			case "D":
+				if m.currentView == viewCategory && m.categoryDescription != "" {
+					m.categoryExpanded = !m.categoryExpanded
+				}
+				return m, nil
+			case "T":
+				m.ThemeEditor = newThemeEditorModel(m.Colors)
+				m.State = stateThemeEditor
+				return m, textinput.Blink
+			case "[", "]":
+				if len(m.currentPosts) == 0 {
+					return m, nil
+				}
+				if msg.String() == "]" && m.postCursor < len(m.currentPosts)-1 {
+					m.postCursor++
+				} else if msg.String() == "[" && m.postCursor > 0 {
+					m.postCursor--
+				}
+				m.renderPosts()
+				m.Viewport.SetYOffset(m.postOffsets[m.postCursor])
+				return m, nil
+			case "L":
+				post, ok := m.focusedPost()
+				if !ok {
+					return m, nil
+				}
+				m.StatusMessage = fmt.Sprintf("Loading likes for post #%d...", post.PostNumber)
+				cmds = append(cmds, func() tea.Msg {
+					users, err := m.Client.GetPostLikers(post.ID)
+					if err != nil {
+						return likersLoadErrorMsg{err: err}
+					}
+					return likersLoadedMsg{post: post, users: users}
+				})
+				return m, tea.Batch(cmds...)
+			case "E":
+				post, ok := m.focusedPost()
+				if !ok {
 					return m, nil
 				}
-				m.isRefreshingTopics = true
-				m.StatusMessage = "Refreshing topics..."
+				if !post.Edited() {
+					m.StatusMessage = fmt.Sprintf("Post #%d has not been edited", post.PostNumber)
+					return m, nil
+				}
+				m.StatusMessage = fmt.Sprintf("Loading edit history of post #%d...", post.PostNumber)
 				cmds = append(cmds, func() tea.Msg {
-					response, err := m.Client.RefreshTopics()
+					revision, err := m.Client.GetPostRevision(post.ID, "latest")
+					if err != nil {
+						return revisionLoadErrorMsg{err: err}
+					}
+					return revisionLoadedMsg{post: post, revision: revision}
+				})
+				return m, tea.Batch(cmds...)
+			case "O":
+				post, ok := m.focusedPost()
+				if !ok {
+					return m, nil
+				}
+				links := extractLinks(post.Cooked, m.Client.BaseURL())
+				if len(links) == 0 {
+					m.StatusMessage = fmt.Sprintf("Post #%d has no links", post.PostNumber)
+					return m, nil
+				}
+				title := fmt.Sprintf("Links in post #%d by %s", post.PostNumber, post.Username)
+				m.Links = newLinksModel(title, links, m.Width, m.Height)
+				m.State = stateLinks
+				return m, nil
+			case "ctrl+a":
+				if m.isRefreshingTopics {
+					return m, nil
+				}
+				return m, m.switchAccount()
+			case "r":
+				if m.failedTopicID != 0 && m.failedTopicID == m.currentTopicID {
+					m.currentPosts = nil
+					m.StatusMessage = ""
+					return m, m.openTopic(m.failedTopicID)
+				}
+				if m.currentTopicID == 0 || len(m.currentPosts) == 0 {
+					return m, nil
+				}
+				if m.CurrentUser == nil {
+					m.StatusMessage = "Log in to reply"
+					return m, nil
+				}
+				var replyTo *discourse.Post
+				// Replies to the first post are replies to the topic itself
+				if post, ok := m.focusedPost(); ok && post.PostNumber > 1 {
+					replyTo = &post
+				}
+				title := fmt.Sprintf("topic %d", m.currentTopicID)
+				if topic, ok := m.viewedTopic(); ok {
+					if topic.Closed && !m.isModerator() {
+						m.StatusMessage = "This topic is closed to new replies"
+						return m, nil
+					}
+					title = topic.Title
+				}
+				m.NewTopicForm = InitialReplyModel(m.Client, m.currentTopicID, title, replyTo, m.Width, m.Height-4)
+				m.State = stateNewTopic
+				return m, textarea.Blink
+			case "u":
+				post, ok := m.focusedPost()
+				if !ok {
+					return m, nil
+				}
+				m.StatusMessage = fmt.Sprintf("Loading profile of %s...", post.Username)
+				client := m.Client
+				return m, func() tea.Msg {
+					user, err := client.GetUser(post.Username)
 					if err != nil {
-						return topicsRefreshErrorMsg{err: err}
+						return userProfileLoadErrorMsg{err: err}
+					}
+					return userProfileLoadedMsg{user: user}
+				}
+			case "ctrl+e":
+				topic, ok := m.viewedTopic()
+				if !ok {
+					return m, nil
+				}
+				if m.CurrentUser == nil {
+					m.StatusMessage = "Log in to edit topics"
+					return m, nil
+				}
+				owner := topic.CreatorUsername == m.CurrentUser.Username
+				if len(m.currentPosts) > 0 && m.currentPosts[0].PostNumber == 1 {
+					owner = m.currentPosts[0].Username == m.CurrentUser.Username
+				}
+				if !owner && !m.isModerator() {
+					m.StatusMessage = "Only the topic's author and moderators can edit it"
+					return m, nil
+				}
+				m.NewTopicForm = InitialEditTopicModel(m.Client, topic, m.Width, m.Height-4)
+				m.State = stateNewTopic
+				return m, textinput.Blink
+			case "U":
+				post, ok := m.focusedPost()
+				if !ok {
+					return m, nil
+				}
+				if m.CurrentUser == nil {
+					m.StatusMessage = "Log in to mark topics unread"
+					return m, nil
+				}
+				topicID := m.currentTopicID
+				client := m.Client
+				m.StatusMessage = fmt.Sprintf("Marking unread from post #%d...", post.PostNumber)
+				return m, func() tea.Msg {
+					if err := client.MarkTopicUnread(topicID, post.PostNumber); err != nil {
+						return topicMarkUnreadErrorMsg{err: err}
 					}
-					categories, catErr := m.Client.GetCategories()
-					if catErr != nil {
-						log.Printf("Warning: failed to fetch categories during refresh: %v", catErr)
+					return topicMarkedUnreadMsg{topicID: topicID, postNumber: post.PostNumber}
+				}
+			case "ctrl+b":
+				post, ok := m.focusedPost()
+				if !ok {
+					return m, nil
+				}
+				if m.CurrentUser == nil {
+					m.StatusMessage = "Bookmarks are only available when logged in"
+					return m, nil
+				}
+				m.bookmarkPost = &post
+				m.reminderInput = textinput.New()
+				m.reminderInput.Placeholder = "no reminder (or 2h, 3d, 1w, tomorrow, 2025-07-01 09:00)"
+				m.reminderInput.Width = 50
+				m.reminderInput.Focus()
+				return m, textinput.Blink
+			case "l":
+				post, ok := m.focusedPost()
+				if !ok {
+					return m, nil
+				}
+				if m.CurrentUser == nil {
+					m.StatusMessage = "Log in to like posts"
+					return m, nil
+				}
+				if post.Username == m.CurrentUser.Username {
+					m.StatusMessage = "You can't like your own post"
+					return m, nil
+				}
+				like, _ := likeSummary(post)
+				if like.Acted && !like.CanUndo {
+					m.StatusMessage = "This like can no longer be removed; the forum only allows undoing a like for a short while"
+					return m, nil
+				}
+				client := m.Client
+				liked := !like.Acted
+				cmds = append(cmds, func() tea.Msg {
+					var updated *discourse.Post
+					var err error
+					if liked {
+						updated, err = client.PerformPostAction(post.ID, discourse.PostActionLike, false)
 					} else {
-						categoryMap := make(map[int]struct {
-							Name  string
-							Color string
-						})
-						for _, category := range categories.CategoryList.Categories {
-							categoryMap[category.ID] = struct {
-								Name  string
-								Color string
-							}{
-								Name:  category.Name,
-								Color: category.Color,
-							}
-						}
-						for i := range response.TopicList.Topics {
-							if cat, ok := categoryMap[response.TopicList.Topics[i].CategoryID]; ok {
-								response.TopicList.Topics[i].CategoryName = cat.Name
-								response.TopicList.Topics[i].CategoryColor = cat.Color
-							}
-						}
+						updated, err = client.RemovePostAction(post.ID, discourse.PostActionLike)
+					}
+					if err != nil {
+						return postLikeErrorMsg{err: err}
+					}
+					return postLikeToggledMsg{postID: post.ID, liked: liked, updated: updated}
+				})
+				return m, tea.Batch(cmds...)
+			case "V":
+				post, ok := m.focusedPost()
+				if !ok {
+					return m, nil
+				}
+				if m.rawPosts == nil {
+					m.rawPosts = make(map[int]bool)
+				}
+				m.rawPosts[post.ID] = !m.rawPosts[post.ID]
+				m.renderPosts()
+				m.Viewport.SetYOffset(m.postOffsets[m.postCursor])
+				return m, nil
+			case "J":
+				post, ok := m.focusedPost()
+				if !m.Debug || !ok {
+					return m, nil
+				}
+				m.StatusMessage = fmt.Sprintf("Fetching JSON of post #%d...", post.PostNumber)
+				cmds = append(cmds, func() tea.Msg {
+					data, err := m.Client.GetPostJSON(post.ID)
+					if err != nil {
+						return postJSONLoadErrorMsg{err: err}
 					}
-					return topicsRefreshedMsg{response: response}
+					return postJSONLoadedMsg{post: post, data: data}
 				})
 				return m, tea.Batch(cmds...)
+			case "X":
+				return m.toggleTopicStatus("closed")
+			case "A":
+				return m.toggleTopicStatus("archived")
+			case "/", "ctrl+f":
+				m.Searching = !m.Searching
+				m.fullSearch = msg.String() == "ctrl+f"
+				if m.fullSearch {
+					m.Search.Placeholder = "Search the whole forum..."
+				} else {
+					m.Search.Placeholder = "Search topics..."
+				}
+				if m.Searching {
+					return m, m.Search.Focus()
+				}
+				return m, nil
+			case "R":
+				if m.isRefreshingTopics {
+					return m, nil
+				}
+				cmds = append(cmds, m.refreshTopics())
+				if m.Client.RateLimitedFor() > 0 {
+					m.StatusMessage = "Rate limited by the forum — refreshing anyway..."
+				}
+				return m, tea.Batch(cmds...)
 			case "m":
 				if m.isLoadingMore || m.MoreTopicsURL == "" {
 					return m, nil
 				}
 				m.isLoadingMore = true
 				m.StatusMessage = "Loading more topics..."
+				moreURL, view := m.MoreTopicsURL, m.currentView
 				cmds = append(cmds, func() tea.Msg {
-					response, err := m.Client.GetMoreTopics(m.MoreTopicsURL)
+					response, err := m.Client.GetMoreTopics(moreURL)
 					if err != nil {
 						return moreTopicsLoadErrorMsg{err: err}
 					}
-					categories, catErr := m.Client.GetCategories()
-					if catErr != nil {
-						log.Printf("Warning: failed to fetch categories for more topics: %v", catErr)
-					} else {
-						categoryMap := make(map[int]struct {
-							Name  string
-							Color string
-						})
-						for _, category := range categories.CategoryList.Categories {
-							categoryMap[category.ID] = struct {
-								Name  string
-								Color string
-							}{
-								Name:  category.Name,
-								Color: category.Color,
-							}
-						}
-						for i := range response.TopicList.Topics {
-							if cat, ok := categoryMap[response.TopicList.Topics[i].CategoryID]; ok {
-								response.TopicList.Topics[i].CategoryName = cat.Name
-								response.TopicList.Topics[i].CategoryColor = cat.Color
-							}
-						}
-					}
-					return moreTopicsLoadedMsg{response: response}
+					return moreTopicsLoadedMsg{response: response, view: view}
 				})
 				return m, tea.Batch(cmds...)
 			case "M":
@@ -688,9 +2978,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, nil
 				}
 				m.isLoadingAll = true
-				m.StatusMessage = "Loading all topics (this may take a while)..."
-				cmds = append(cmds, func() tea.Msg {
-					response, err := m.Client.LoadAllTopics(20)
+				m.StatusMessage = "Loading all topics (this may take a while, esc to stop)..."
+				ctx, cancel := context.WithCancel(context.Background())
+				m.loadAllCancel = cancel
+				cmds = append(cmds, m.watchCooldown(), func() tea.Msg {
+					response, err := m.Client.LoadAllTopicsContext(ctx, 20)
 					if err != nil {
 						return loadAllTopicsErrorMsg{err: err}
 					}
@@ -702,8 +2994,19 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.Searching = false
 					return m, nil
 				}
-				if m.Fullscreen {
-					m.Fullscreen = false
+				if m.Layout != config.LayoutSplit {
+					leave := m.leaveTopic()
+					m.Layout = config.LayoutSplit
+					m.resizeLayout()
+					return m, tea.Batch(leave, m.resumeRefresh())
+				}
+				if m.currentView != viewLatest {
+					m.switchView(viewLatest, nil, "")
+					return m, nil
+				}
+				// Only abort fetches once there's nothing else to close
+				if m.cancelBulkFetch() {
+					m.coolingDown = false
 					return m, nil
 				}
 			case "enter":
@@ -729,76 +3032,62 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					if m.isLoadingPosts {
 						return m, nil
 					}
-					m.isLoadingPosts = true
-					m.Viewport.SetContent("Loading posts...")
-					selectedTopicID := i.topic.ID
-					// First load only the first page to show content quickly.
-					cmd1 := func() tea.Msg {
-						postsPage, err := m.Client.GetTopicPostsPage(selectedTopicID, 1)
-						if err != nil {
-							return postsLoadErrorMsg{err: err}
-						}
-						return postsLoadedMsg{posts: postsPage}
-					}
-					// Then load the full topic in background.
-					cmd2 := func() tea.Msg {
-						fullPosts, err := m.Client.GetTopicPosts(selectedTopicID)
-						if err != nil {
-							return postsLoadErrorMsg{err: err}
-						}
-						return postsLoadedMsg{posts: fullPosts}
+					m.currentPosts = nil
+					m.postCursor = 0
+					cmds = append(cmds, m.openTopic(i.topic.ID))
+					m.resumePosition(i.topic)
+				}
+			}
+		case likersLoadedMsg:
+			body := "No likes yet"
+			if len(msg.users) > 0 {
+				var b strings.Builder
+				for _, user := range msg.users {
+					if user.Name != "" {
+						fmt.Fprintf(&b, "%s (%s)\n", user.Username, user.Name)
+					} else {
+						fmt.Fprintf(&b, "%s\n", user.Username)
 					}
-					cmds = append(cmds, cmd1, cmd2)
-				}
-			}
-		case postsLoadedMsg:
-			m.isLoadingPosts = false
-			var content strings.Builder
-			postContentWidth := m.Viewport.Width - 2
-			if postContentWidth < 1 {
-				postContentWidth = 1
-			}
-			for _, post := range msg.posts.PostStream.Posts {
-				content.WriteString(FormatPost(post, postContentWidth))
-				content.WriteString("\n\n---\n\n")
-			}
-			m.Viewport.SetContent(content.String())
-			m.Viewport.GotoTop()
-		case postsLoadErrorMsg:
-			m.isLoadingPosts = false
-			errorContentWidth := m.Viewport.Width - 2
-			if errorContentWidth < 1 {
-				errorContentWidth = 1
-			}
-			errorStyle := lipgloss.NewStyle().Width(errorContentWidth)
-			m.Viewport.SetContent(errorStyle.Render(fmt.Sprintf("Error fetching posts: %v", msg.err)))
+				}
+				body = b.String()
+			}
+			m.Overlay = newOverlayModel(fmt.Sprintf("Likes on post #%d by %s", msg.post.PostNumber, msg.post.Username), body, m.Width, m.Height)
+			m.State = stateOverlay
+			return m, nil
+		case revisionLoadedMsg:
+			title := fmt.Sprintf("Latest edit of post #%d by %s", msg.post.PostNumber, msg.revision.Username)
+			if !msg.revision.CreatedAt.IsZero() {
+				title += " at " + msg.revision.CreatedAt.Local().Format("2006-01-02 15:04")
+			}
+			m.Overlay = newOverlayModel(title, renderLineDiff(msg.revision.Before, msg.revision.After), m.Width, m.Height)
+			m.State = stateOverlay
+			return m, nil
+		case revisionLoadErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error loading edit history: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Error loading edit history: %v", msg.err)
+		case postJSONLoadedMsg:
+			m.Overlay = newOverlayModel(fmt.Sprintf("Post #%d (id %d) JSON", msg.post.PostNumber, msg.post.ID), string(msg.data), m.Width, m.Height)
+			m.State = stateOverlay
+			return m, nil
+		case postJSONLoadErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error fetching post JSON: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Error fetching post JSON: %v", msg.err)
+		case likersLoadErrorMsg:
+			m.StatusMessage = fmt.Sprintf("Error loading likes: %s", discourse.ErrorMessage(msg.err))
+			log.Printf("Error loading likes: %v", msg.err)
 		case tea.WindowSizeMsg:
 			m.Width = msg.Width
 			m.Height = msg.Height
 
 			if !m.Ready {
 				m.Ready = true
+				cmds = append(cmds, m.startPrefetch())
 			}
 
 			if m.State == stateNewTopic {
-				m.NewTopicForm.width = msg.Width
-				m.NewTopicForm.height = msg.Height - 4
-				m.NewTopicForm.titleInput.Width = msg.Width - 4
-				m.NewTopicForm.contentInput.SetWidth(msg.Width - 4)
-				m.NewTopicForm.contentInput.SetHeight((msg.Height - 4) / 3)
-				m.NewTopicForm.categoryInput.Width = msg.Width - 4
-				m.NewTopicForm.tagsInput.Width = msg.Width - 4
+				m.NewTopicForm.resize(msg.Width, msg.Height-4)
 			} else {
-				if m.Fullscreen {
-					m.Viewport.Width = msg.Width
-					m.Viewport.Height = msg.Height
-				} else {
-					listHeight := msg.Height / 2
-					m.List.SetWidth(msg.Width)
-					m.List.SetHeight(listHeight)
-					m.Viewport.Width = msg.Width
-					m.Viewport.Height = msg.Height - listHeight - 1
-				}
+				m.resizeLayout()
 			}
 		}
 
@@ -807,10 +3096,504 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.Viewport, cmd = m.Viewport.Update(msg)
 		cmds = append(cmds, cmd)
+		m.noteReadPosts()
 	}
 	return m, tea.Batch(cmds...)
 }
 
+// postsLoaded shows a topic's posts, or the next chunk of them while the
+// topic streams in.
+func (m *Model) postsLoaded(msg postsLoadedMsg) tea.Cmd {
+	var cmds []tea.Cmd
+	if msg.next != nil {
+		cmds = append(cmds, msg.next)
+	}
+	if msg.topicID != m.currentTopicID {
+		return tea.Batch(cmds...)
+	}
+	streaming := m.postsStreaming
+	if msg.next != nil {
+		m.postsStreaming = true
+		// Posts shown from the cache already cover the early chunks
+		shown := m.currentPosts
+		if len(shown) > 0 && shown[0].TopicID == msg.topicID && len(shown) >= len(msg.posts.PostStream.Posts) {
+			return tea.Batch(cmds...)
+		}
+		// Nor should chunks that end before the window around a jump
+		chunk := msg.posts.PostStream.Posts
+		if m.postsWindow && len(shown) > 0 && len(chunk) > 0 && chunk[len(chunk)-1].PostNumber < shown[len(shown)-1].PostNumber {
+			return tea.Batch(cmds...)
+		}
+	} else if streaming && !msg.full {
+		// Once chunks are streaming in, the first page would only
+		// shrink the topic again
+		return tea.Batch(cmds...)
+	} else {
+		m.postsStreaming = false
+	}
+	m.isLoadingPosts = false
+	m.failedTopicID = 0
+	if msg.full {
+		m.postsCancel = nil
+	}
+	// Later chunks repeat the posts already shown; anything else may
+	// have been edited or liked since
+	if !streaming {
+		m.postCache = nil
+	}
+	m.noteNetworkResult(nil)
+	if msg.topic != nil {
+		m.topicDetail = msg.topic
+	}
+	// Keep the reader on the same post once the window is replaced
+	if m.postsWindow && !msg.window && m.jumpToPost == 0 && m.postCursor < len(m.currentPosts) {
+		m.jumpToPost = m.currentPosts[m.postCursor].PostNumber
+	}
+	m.postsWindow = msg.window
+	m.currentPosts = msg.posts.PostStream.Posts
+	if config.Current.HideWhispers {
+		var visible []discourse.Post
+		for _, post := range m.currentPosts {
+			if !post.IsWhisper() {
+				visible = append(visible, post)
+			}
+		}
+		m.currentPosts = visible
+	}
+	jumped := false
+	if m.jumpToPost > 0 {
+		for i, post := range m.currentPosts {
+			if post.PostNumber >= m.jumpToPost {
+				m.postCursor = i
+				m.jumpToPost = 0
+				jumped = true
+				break
+			}
+		}
+		if msg.full {
+			m.jumpToPost = 0
+		}
+	}
+	if m.postCursor >= len(m.currentPosts) {
+		m.postCursor = 0
+	}
+	yOffset := m.Viewport.YOffset
+	m.renderPosts()
+	switch {
+	case m.restoreYOffset > 0 && !jumped:
+		m.Viewport.SetYOffset(m.restoreYOffset)
+		// Focus the post the reader was at, for [ and ]
+		cursor := m.postCursor
+		for i, offset := range m.postOffsets {
+			if offset <= m.Viewport.YOffset {
+				cursor = i
+			}
+		}
+		if cursor != m.postCursor {
+			m.postCursor = cursor
+			m.renderPosts()
+			m.Viewport.SetYOffset(m.restoreYOffset)
+		}
+		// Keep restoring until enough of the topic is in
+		if m.Viewport.YOffset == m.restoreYOffset || msg.full {
+			m.restoreYOffset = 0
+		}
+	case streaming && !jumped:
+		// Later chunks only add posts below; stay where the reader is
+		m.Viewport.SetYOffset(yOffset)
+	case m.postCursor > 0 && len(m.postOffsets) > m.postCursor:
+		// The first post starts below the topic header
+		m.Viewport.SetYOffset(m.postOffsets[m.postCursor])
+	default:
+		m.Viewport.GotoTop()
+	}
+	m.noteReadPosts()
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) postsLoadError(msg postsLoadErrorMsg) tea.Cmd {
+	// Errors of a topic no longer open mustn't replace the one on screen
+	if msg.topicID != m.currentTopicID {
+		return nil
+	}
+	if errors.Is(msg.err, context.Canceled) {
+		// A newer topic may have replaced the cancelled fetch
+		if m.postsCancel == nil {
+			m.StatusMessage = "Stopped loading the rest of the topic"
+		}
+		return nil
+	}
+	m.isLoadingPosts = false
+	m.postsCancel = nil
+	m.noteNetworkResult(msg.err)
+	errorContentWidth := m.Viewport.Width - 2
+	if errorContentWidth < 1 {
+		errorContentWidth = 1
+	}
+	errorStyle := lipgloss.NewStyle().Width(errorContentWidth)
+	m.failedTopicID = msg.topicID
+	m.Viewport.SetContent(errorStyle.Render(fmt.Sprintf("Error fetching posts: %s\n\nPress r to try again.", discourse.ErrorMessage(msg.err))))
+	return m.watchRateLimit()
+}
+
+// renderedPost is a post as formatted by renderPosts, kept so moving the
+// cursor or streaming in more posts only formats the posts not yet shown.
+type renderedPost struct {
+	width int
+	raw   bool
+	text  string
+	lines int
+}
+
+// renderPost formats post for the viewport, reusing the last rendering
+// while the width and raw mode are unchanged.
+func (m *Model) renderPost(post discourse.Post, width int) renderedPost {
+	raw := m.rawPosts[post.ID]
+	if cached, ok := m.postCache[post.ID]; ok && cached.width == width && cached.raw == raw {
+		return cached
+	}
+	text := FormatPost(post, width)
+	if raw {
+		text = formatRawPost(post, width)
+	}
+	rendered := renderedPost{width: width, raw: raw, text: text, lines: strings.Count(text, "\n")}
+	if m.postCache == nil {
+		m.postCache = make(map[int]renderedPost)
+	}
+	m.postCache[post.ID] = rendered
+	return rendered
+}
+
+// renderPosts fills the viewport with the open topic's posts, marking the
+// one under the post cursor, and records where each post starts.
+func (m *Model) renderPosts() {
+	var content strings.Builder
+	postContentWidth := m.Viewport.Width - 2
+	if postContentWidth < 1 {
+		postContentWidth = 1
+	}
+	if m.topicDetail != nil && m.topicDetail.ID == m.currentTopicID {
+		content.WriteString(topicHeader(*m.topicDetail, postContentWidth))
+		content.WriteString("\n\n")
+	}
+	m.postOffsets = m.postOffsets[:0]
+	lines := strings.Count(content.String(), "\n")
+	for i, post := range m.currentPosts {
+		m.postOffsets = append(m.postOffsets, lines)
+		divider := postDivider(post.PostNumber, postContentWidth)
+		content.WriteString(divider)
+		content.WriteString("\n")
+		rendered := m.renderPost(post, postContentWidth)
+		formatted := rendered.text
+		if i == m.postCursor && len(m.currentPosts) > 1 {
+			formatted = lipgloss.NewStyle().Foreground(config.SelectedItemStyle.GetForeground()).Render("▶ ") + formatted
+		}
+		content.WriteString(formatted)
+		content.WriteString("\n\n")
+		lines += strings.Count(divider, "\n") + rendered.lines + 3
+	}
+	m.Viewport.SetContent(content.String())
+}
+
+// topicHeader is shown above a topic's posts once its details are loaded.
+func topicHeader(topic discourse.Topic, width int) string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Width(width).Render(config.TitleStyle.Render(topic.Title)))
+	var labels []string
+	if category := categoryLabel(topic); category != "" {
+		labels = append(labels, "["+category+"]")
+	}
+	if len(topic.Tags) > 0 {
+		labels = append(labels, "{"+strings.Join(topic.Tags, ", ")+"}")
+	}
+	if len(labels) > 0 {
+		b.WriteString("\n" + strings.Join(labels, " "))
+	}
+	var stats []string
+	if topic.CreatorUsername != "" {
+		stats = append(stats, "Started by @"+topic.CreatorUsername)
+	}
+	stats = append(stats, pluralize(topic.PostsCount, "post", "posts"))
+	if topic.ParticipantCount > 0 {
+		stats = append(stats, pluralize(topic.ParticipantCount, "participant", "participants"))
+	}
+	stats = append(stats, pluralize(topic.Views, "view", "views"), topic.CreatedAt.Local().Format("2006-01-02"))
+	b.WriteString("\n" + lipgloss.NewStyle().Width(width).Render(config.StatusStyle.Render(strings.Join(stats, " • "))))
+	return b.String()
+}
+
+// viewedTopic returns the topic whose posts are shown, which needn't be in
+// the current list (e.g. one opened from a notification).
+func (m Model) viewedTopic() (discourse.Topic, bool) {
+	if m.currentTopicID == 0 {
+		return discourse.Topic{}, false
+	}
+	if m.topicDetail != nil && m.topicDetail.ID == m.currentTopicID {
+		return *m.topicDetail, true
+	}
+	for _, topic := range m.Topics {
+		if topic.ID == m.currentTopicID {
+			return topic, true
+		}
+	}
+	return discourse.Topic{}, false
+}
+
+// profileKeys is the help for the keys of the profile overlay.
+func profileKeys(user *discourse.UserProfile) string {
+	switch {
+	case !user.CanFollow:
+		return "a: recent activity | "
+	case user.IsFollowed:
+		return "a: recent activity | f: unfollow | "
+	default:
+		return "a: recent activity | f: follow | "
+	}
+}
+
+// formatUserProfile is the body of the profile overlay.
+func formatUserProfile(user *discourse.UserProfile) string {
+	var b strings.Builder
+	if user.Name != "" {
+		fmt.Fprintf(&b, "%s (@%s)\n", user.Name, user.Username)
+	} else {
+		fmt.Fprintf(&b, "@%s\n", user.Username)
+	}
+	fmt.Fprintf(&b, "Trust level: %d\n", user.TrustLevel)
+	switch {
+	case user.Admin:
+		b.WriteString("Admin\n")
+	case user.Moderator:
+		b.WriteString("Moderator\n")
+	}
+	if !user.CreatedAt.IsZero() {
+		fmt.Fprintf(&b, "Joined: %s\n", user.CreatedAt.Local().Format("2006-01-02"))
+	}
+	fmt.Fprintf(&b, "Posts: %d • Badges: %d\n", user.PostCount, user.BadgeCount)
+	if user.FollowSupported {
+		fmt.Fprintf(&b, "Followers: %d • Following: %d\n", user.TotalFollowers, user.TotalFollowing)
+		if user.IsFollowed {
+			b.WriteString("You follow them\n")
+		}
+	}
+	bio := strings.TrimSpace(user.Bio)
+	if bio == "" {
+		bio = strings.TrimSpace(convertHTMLToText(user.BioExcerpt))
+	}
+	if bio != "" {
+		b.WriteString("\n" + bio + "\n")
+	}
+	return b.String()
+}
+
+// postDivider is the full-width rule drawn above each post, labelled with
+// the post number.
+func postDivider(postNumber, width int) string {
+	char := "─"
+	switch config.Current.PostDivider {
+	case "double":
+		char = "═"
+	case "thick":
+		char = "━"
+	case "ascii":
+		char = "-"
+	}
+	label := fmt.Sprintf("%s #%d ", strings.Repeat(char, 2), postNumber)
+	rest := width - lipgloss.Width(label)
+	if rest < 0 {
+		rest = 0
+	}
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("62")).Render(label + strings.Repeat(char, rest))
+}
+
+// noteReadPosts records how far down the open topic the reader has got:
+// the last post that starts inside the viewport.
+func (m *Model) noteReadPosts() {
+	if m.readPaused {
+		return
+	}
+	bottom := m.Viewport.YOffset + m.Viewport.Height
+	for i, offset := range m.postOffsets {
+		if offset >= bottom || i >= len(m.currentPosts) {
+			break
+		}
+		m.readThrough = max(m.readThrough, m.currentPosts[i].PostNumber)
+	}
+}
+
+// flushRead reports the posts of the open topic that came on screen since
+// it was opened as read, from after the last one the forum already had as
+// read. Anonymous sessions have no read state to update.
+func (m *Model) flushRead() tea.Cmd {
+	if m.CurrentUser == nil || m.currentTopicID == 0 {
+		return nil
+	}
+	topicID := m.currentTopicID
+	lastRead := m.markedThrough
+	if m.topicDetail != nil && m.topicDetail.ID == topicID {
+		lastRead = max(lastRead, m.topicDetail.LastReadPostNumber)
+	}
+	for _, topic := range m.Topics {
+		if topic.ID == topicID {
+			lastRead = max(lastRead, topic.LastReadPostNumber)
+		}
+	}
+	through := m.readThrough
+	if through <= lastRead {
+		return nil
+	}
+	m.markedThrough = through
+	client := m.Client
+	return func() tea.Msg {
+		if err := client.MarkTopicRead(topicID, lastRead+1, through); err != nil {
+			return topicMarkReadErrorMsg{err: err}
+		}
+		return topicMarkedReadMsg{topicID: topicID, postNumber: through}
+	}
+}
+
+// focusedPost returns the post under the post cursor in the open topic.
+func (m Model) focusedPost() (discourse.Post, bool) {
+	if m.postCursor < 0 || m.postCursor >= len(m.currentPosts) {
+		return discourse.Post{}, false
+	}
+	return m.currentPosts[m.postCursor], true
+}
+
+// likeSummary returns the like entry of a post's actions summary.
+func likeSummary(post discourse.Post) (discourse.ActionsSummary, bool) {
+	for _, action := range post.ActionsSummary {
+		if action.ID == discourse.PostActionLike {
+			return action, true
+		}
+	}
+	return discourse.ActionsSummary{ID: discourse.PostActionLike}, false
+}
+
+// setLiked updates a post's like count and state after the user liked or
+// unliked it, for when the forum's answer doesn't carry the new summary.
+func setLiked(post *discourse.Post, liked bool) {
+	like, ok := likeSummary(*post)
+	if like.Acted == liked {
+		return
+	}
+	like.Acted = liked
+	like.CanUndo = liked
+	if liked {
+		like.Count++
+	} else if like.Count > 0 {
+		like.Count--
+	}
+	if !ok {
+		post.ActionsSummary = append(post.ActionsSummary, like)
+		return
+	}
+	for i := range post.ActionsSummary {
+		if post.ActionsSummary[i].ID == discourse.PostActionLike {
+			post.ActionsSummary[i] = like
+		}
+	}
+}
+
+// visitBanner summarises what changed since the last session, until the
+// first key press.
+func (m Model) visitBanner() string {
+	if m.visitSeen || m.LastVisit.IsZero() {
+		return ""
+	}
+	updated := 0
+	for _, topic := range m.Topics {
+		if topic.BumpedAt.After(m.LastVisit) || topic.CreatedAt.After(m.LastVisit) {
+			updated++
+		}
+	}
+	if updated == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s updated since your last visit", pluralize(updated, "topic", "topics"))
+}
+
+// pageList moves the list cursor a visible page at a time, or to either end.
+// These keys would otherwise also scroll the viewport.
+func (m *Model) pageList(key string) bool {
+	count := len(m.List.VisibleItems())
+	if count == 0 {
+		return false
+	}
+	perPage := max(m.List.Paginator.PerPage, 1)
+	switch key {
+	case "pgdown":
+		m.List.Select(min(m.List.Index()+perPage, count-1))
+	case "pgup":
+		m.List.Select(max(m.List.Index()-perPage, 0))
+	case "home":
+		m.List.Select(0)
+	case "end":
+		m.List.Select(count - 1)
+	default:
+		return false
+	}
+	return true
+}
+
+// resizeLayout sizes the list and viewport for the current layout.
+func (m *Model) resizeLayout() {
+	switch m.Layout {
+	case config.LayoutViewport:
+		m.Viewport.Width = m.Width
+		m.Viewport.Height = m.Height
+	case config.LayoutList:
+		m.List.SetWidth(m.Width)
+		m.List.SetHeight(m.Height)
+	default:
+		listHeight := m.Height / 2
+		m.List.SetWidth(m.Width)
+		m.List.SetHeight(listHeight)
+		m.Viewport.Width = m.Width
+		m.Viewport.Height = m.Height - listHeight - 1
+	}
+}
+
+func (m *Model) saveLayout() {
+	config.Current.Layout = m.Layout
+	m.saveSettings()
+}
+
+// saveSettings writes config.Current back to settings.txt.
+func (m *Model) saveSettings() {
+	if m.SettingsPath == "" {
+		return
+	}
+	if err := config.SaveSettings(m.SettingsPath, config.Current); err != nil {
+		log.Printf("Failed to save settings: %v", err)
+	}
+}
+
+// loadCategory switches the list to the topics of a category.
+func (m *Model) loadCategory(categoryID int, name, slug string) tea.Cmd {
+	if name == "" {
+		name = fmt.Sprintf("#%d", categoryID)
+	}
+	m.StatusMessage = fmt.Sprintf("Loading topics in %s...", name)
+	client := m.Client
+	return func() tea.Msg {
+		response, err := client.GetCategoryTopics(categoryID, slug)
+		if err != nil {
+			return categoryTopicsLoadErrorMsg{err: err}
+		}
+		var description string
+		if categories, err := client.GetCategories(); err == nil {
+			for _, category := range categories.CategoryList.Categories {
+				if category.ID == categoryID {
+					description = category.Description
+					break
+				}
+			}
+		}
+		return categoryTopicsLoadedMsg{name: name, description: description, response: response}
+	}
+}
+
 func (m Model) View() string {
 	if !m.Ready {
 		return "\nInitializing..."
@@ -820,12 +3603,57 @@ func (m Model) View() string {
 		return m.NewTopicForm.View()
 	}
 
+	if m.State == stateOverlay {
+		return m.Overlay.View()
+	}
+
+	if m.State == stateLinks {
+		return m.Links.View()
+	}
+
+	if m.State == stateCategories {
+		return m.Categories.View()
+	}
+
+	if m.State == stateActivity {
+		return m.Activity.View()
+	}
+
+	if m.State == stateChat {
+		return m.Chat.View()
+	}
+
+	if m.State == stateNotifications {
+		return m.Notifications.View()
+	}
+
+	if m.State == stateThemeEditor {
+		// Preview the edited colors on the real list
+		m.List.SetWidth(m.Width - 2)
+		m.List.SetHeight(m.Height - len(themeFields) - 10)
+		return lipgloss.JoinVertical(lipgloss.Left, m.ThemeEditor.View(), "", m.List.View())
+	}
+
 	headerHeight := 2
 	helpHeight := 2
+	banner := m.categoryBanner()
+	if banner != "" {
+		headerHeight += lipgloss.Height(banner)
+	}
 	availableHeight := m.Height - headerHeight - helpHeight - 2
 	listHeight := (availableHeight * 2) / 3
 	viewportHeight := availableHeight - listHeight
 
+	headerText := m.InstanceURL
+	if m.CurrentUser != nil {
+		headerText += " as @" + m.CurrentUser.Username
+	}
+	if pause := m.Client.RateLimitedFor(); pause > 0 {
+		// Only the colour: ErrorStyle's padding would push the centered
+		// header off balance
+		notice := fmt.Sprintf("rate limited — pausing background sync for %ds", int(pause.Seconds())+1)
+		headerText += " • " + lipgloss.NewStyle().Foreground(config.ErrorStyle.GetForeground()).Render(notice)
+	}
 	instanceHeader := lipgloss.NewStyle().
 		Bold(true).
 		Foreground(lipgloss.Color("62")).
@@ -834,18 +3662,61 @@ func (m Model) View() string {
 		BorderForeground(lipgloss.Color("62")).
 		Width(m.Width - 2).
 		Align(lipgloss.Center).
-		Render(m.InstanceURL)
+		Render(headerText)
+	if banner != "" {
+		instanceHeader = lipgloss.JoinVertical(lipgloss.Left, instanceHeader, banner)
+	}
 
+	helpText := "Press 'f' for fullscreen, 'ctrl+w' to cycle layout, 'PgUp'/'PgDn'/'Home'/'End' to page, 'ctrl+d'/'ctrl+u' to scroll the posts half a page, 'g g'/'G' to jump to the first/last post, '['/']' to move between posts, 'l' to like or unlike the post, 'L' for likes, 'E' for edits, 'V' for raw HTML, 'O' for the post's links, 'r' to reply (or retry a topic that failed to load), 'ctrl+a' to switch account, 'b' to bookmark the topic, 'ctrl+b' to bookmark the post, 'ctrl+e' to edit the topic's title, category and tags, 'U' to mark the topic unread from the post, 'u' for the author's profile (then 'a' for activity, 'f' to follow), 'c' for the topic's category, 'C' to browse categories, 'g n' for notifications, '1'/'2'/'3'/'4' for latest/top/new/unread topics, 'H' for hot topics, 'o' to open the topic in the browser, 'y' to copy the post's link (or the topic's), 'Y' to copy the topic's link, 'ctrl+y' to copy the post's text, 'D' to expand its description, '/' to search, 'ctrl+f' to search the whole forum, 'R' to refresh, 'm' to load more, 'M' to load all, 'T' to edit colors, 'esc' to exit fullscreen/search"
+	if m.CurrentUser != nil {
+		helpText += ", 'B' for bookmarks"
+	}
+	if m.ChatEnabled && !m.chatUnavailable {
+		helpText += ", 'ctrl+t' for chat"
+	}
+	if m.isModerator() {
+		helpText += ", 'X' to close/open, 'A' to archive"
+	}
+	if m.Debug {
+		helpText += ", 'J' for post JSON"
+	}
 	help := lipgloss.NewStyle().
 		Foreground(lipgloss.Color("240")).
 		Padding(0, 1).
-		Render(fmt.Sprintf("Press 'f' for fullscreen, '/' to search, 'R' to refresh, 'm' to load more, 'M' to load all, 'esc' to exit fullscreen/search • Last refresh: %s", m.LastRefresh.Format("15:04:05")))
+		Render(fmt.Sprintf("%s • Last refresh: %s", helpText, m.LastRefresh.Format("15:04:05")))
 
-	if m.StatusMessage != "" {
+	if m.bookmarkPost != nil {
+		prompt := fmt.Sprintf("Bookmark post #%d, remind: %s (enter to save, esc to cancel)", m.bookmarkPost.PostNumber, m.reminderInput.View())
+		help = config.StatusStyle.Render(prompt)
+		if m.StatusMessage != "" {
+			help = lipgloss.JoinHorizontal(lipgloss.Left, config.ErrorStyle.Render(m.StatusMessage), " • ", help)
+		}
+	} else if m.networkFailures > 0 && !m.isRefreshingTopics {
+		help = lipgloss.JoinHorizontal(lipgloss.Left, config.ErrorStyle.Render("Network unavailable — press R to retry"), " • ", help)
+	} else if m.coolingDown {
+		help = lipgloss.JoinHorizontal(lipgloss.Left, config.StatusStyle.Render("Waiting (rate limit cooldown)… esc to stop"), " • ", help)
+	} else if m.StatusMessage != "" {
 		help = lipgloss.JoinHorizontal(lipgloss.Left, config.StatusStyle.Render(m.StatusMessage), " • ", help)
+	} else if banner := m.visitBanner(); banner != "" {
+		help = lipgloss.JoinHorizontal(lipgloss.Left, config.StatusStyle.Render(banner), " • ", help)
+	}
+
+	if m.Layout == config.LayoutList {
+		m.List.SetWidth(m.Width - 2)
+		m.List.SetHeight(m.Height - headerHeight - helpHeight - 1)
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			instanceHeader,
+			m.List.View(),
+			help,
+		)
 	}
 
-	if m.Fullscreen {
+	if m.Layout == config.LayoutViewport {
+		// Reading a post gets the whole screen, without the category banner
+		if banner != "" {
+			instanceHeader = strings.TrimSuffix(instanceHeader, "\n"+banner)
+		}
 		return lipgloss.JoinVertical(
 			lipgloss.Left,
 			instanceHeader,
@@ -894,16 +3765,48 @@ func (m Model) View() string {
 	return view
 }
 
-func FormatPost(post discourse.Post, contentWidth int) string {
+// categoryCollapsedLines is how much of a long category description shows
+// until it is expanded.
+const categoryCollapsedLines = 2
+
+// categoryBanner renders the description of the category being viewed, or
+// "" outside the category view.
+func (m Model) categoryBanner() string {
+	if m.currentView != viewCategory || m.categoryDescription == "" {
+		return ""
+	}
+	text := strings.Join(strings.Fields(convertHTMLToText(m.categoryDescription)), " ")
+	if text == "" {
+		return ""
+	}
+	width := max(m.Width-4, 1)
+	lines := strings.Split(lipgloss.NewStyle().Width(width).Render(text), "\n")
+	if !m.categoryExpanded && len(lines) > categoryCollapsedLines {
+		const more = " … (D to expand)"
+		lines = lines[:categoryCollapsedLines]
+		last := lipgloss.NewStyle().Width(max(width-lipgloss.Width(more), 1)).Render(strings.TrimSpace(lines[len(lines)-1]))
+		lines[len(lines)-1] = strings.TrimRight(strings.Split(last, "\n")[0], " ") + more
+	}
+	return config.StatusStyle.Padding(0, 1).Render(strings.Join(lines, "\n"))
+}
+
+// postText is a post's body as the plain text FormatPost wraps.
+func postText(post discourse.Post) string {
 	p := bluemonday.UGCPolicy()
 	p.AllowElements("a").AllowAttrs("href").OnElements("a")
 	p.AllowElements("code", "pre", "blockquote", "em", "strong", "br", "p", "div")
+	// Images become placeholders with their alt text, emoji their :code:
+	p.AllowAttrs("alt", "class").OnElements("img")
 
 	sanitizedContent := p.Sanitize(post.Cooked)
 
 	text := convertHTMLToText(sanitizedContent)
 	text = strings.ReplaceAll(text, "\r\n", "\n")
-	text = strings.ReplaceAll(text, "\r", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+func FormatPost(post discourse.Post, contentWidth int) string {
+	text := postText(post)
 
 	potentialParagraphs := strings.Split(text, "\n")
 	var paragraphsSource []string
@@ -918,6 +3821,13 @@ func FormatPost(post discourse.Post, contentWidth int) string {
 		contentWidth = 1
 	}
 	contentWrappingStyle := lipgloss.NewStyle().Width(contentWidth)
+	if post.IsNotice() {
+		contentWrappingStyle = contentWrappingStyle.Italic(true)
+		if len(paragraphsSource) == 0 && post.ActionCode != "" {
+			// Small actions like "closed.enabled" usually have no body
+			paragraphsSource = []string{"[" + strings.ReplaceAll(post.ActionCode, ".", " ") + "]"}
+		}
+	}
 
 	var renderedParagraphs []string
 	for _, paraStr := range paragraphsSource {
@@ -932,6 +3842,19 @@ func FormatPost(post discourse.Post, contentWidth int) string {
 		post.Name,
 		post.Username,
 		post.CreatedAt.Format("2006-01-02 15:04:05"))
+	if post.Edited() {
+		postHeader = strings.Replace(postHeader, "\n", " ✎ edited\n", 1)
+	}
+	if post.IsWhisper() {
+		postHeader = strings.Replace(postHeader, "\n", " [whisper]\n", 1)
+	}
+	if post.ReplyToPostNumber > 0 {
+		postHeader = fmt.Sprintf("↳ in reply to #%d\n", post.ReplyToPostNumber) + postHeader
+	}
+	if post.AcceptedAnswer && config.Current.ShowSolved {
+		badge := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("2")).Render("✓ Accepted Answer")
+		postHeader = badge + "\n" + postHeader
+	}
 
 	postFooter := fmt.Sprintf("Reads: %d | Score: %.1f",
 		post.Reads,
@@ -960,102 +3883,138 @@ func FormatPost(post discourse.Post, contentWidth int) string {
 	}, "\n")
 }
 
-func convertHTMLToText(html string) string {
-	html = strings.ReplaceAll(html, "<br/>", "\n")
-	html = strings.ReplaceAll(html, "<br>", "\n")
-	html = strings.ReplaceAll(html, "</p>", "\n\n")
-	html = strings.ReplaceAll(html, "</div>", "\n")
-	html = strings.ReplaceAll(html, "</blockquote>", "\n")
-
-	var result strings.Builder
-	var currentTag strings.Builder
-	var inTag bool
-	var inAnchor bool
-	var anchorHref string
-	var anchorText strings.Builder
-
-	i := 0
-	for i < len(html) {
-		char := html[i]
-
-		if char == '<' {
-			inTag = true
-			currentTag.Reset()
-		} else if char == '>' && inTag {
-			inTag = false
-			tag := currentTag.String()
-
-			if strings.HasPrefix(tag, "a ") && strings.Contains(tag, "href=") {
-				inAnchor = true
-				anchorText.Reset()
-				start := strings.Index(tag, `href="`) + 6
-				if start > 5 {
-					end := strings.Index(tag[start:], `"`)
-					if end > 0 {
-						anchorHref = tag[start : start+end]
-					}
-				}
-			} else if tag == "/a" && inAnchor {
-				inAnchor = false
-				linkText := anchorText.String()
-				if linkText == anchorHref || strings.TrimSpace(linkText) == "" {
-					result.WriteString(anchorHref)
-				} else {
-					result.WriteString(fmt.Sprintf("%s (%s)", linkText, anchorHref))
-				}
-				anchorHref = ""
-			} else if tag == "code" {
-				result.WriteString("`")
-			} else if tag == "/code" {
-				result.WriteString("`")
-			} else if tag == "pre" {
-				result.WriteString("\n```\n")
-			} else if tag == "/pre" {
-				result.WriteString("\n```\n")
-			} else if tag == "blockquote" {
-				result.WriteString("\n> ")
-			} else if tag == "strong" || tag == "b" {
-				result.WriteString("**")
-			} else if tag == "/strong" || tag == "/b" {
-				result.WriteString("**")
-			} else if tag == "em" || tag == "i" {
-				result.WriteString("*")
-			} else if tag == "/em" || tag == "/i" {
-				result.WriteString("*")
-			}
-		} else if inTag {
-			currentTag.WriteByte(char)
-		} else if inAnchor {
-			anchorText.WriteByte(char)
-		} else {
-			result.WriteByte(char)
+// parseReminder reads a bookmark reminder typed as a duration from now (2h,
+// 3d, 1w), "tomorrow" (8am, as Discourse does) or a local date and time.
+// Empty input means no reminder.
+func parseReminder(input string, now time.Time) (*time.Time, error) {
+	input = strings.ToLower(strings.TrimSpace(input))
+	if input == "" {
+		return nil, nil
+	}
+
+	var at time.Time
+	if input == "tomorrow" {
+		y, mo, d := now.AddDate(0, 0, 1).Date()
+		at = time.Date(y, mo, d, 8, 0, 0, 0, now.Location())
+	} else if n, err := strconv.Atoi(strings.TrimRight(input, "dw")); err == nil && len(input) > 1 && strings.ContainsAny(input[len(input)-1:], "dw") {
+		days := n
+		if strings.HasSuffix(input, "w") {
+			days *= 7
 		}
+		at = now.AddDate(0, 0, days)
+	} else if d, err := time.ParseDuration(input); err == nil {
+		at = now.Add(d)
+	} else if t, err := time.ParseInLocation("2006-01-02 15:04", input, now.Location()); err == nil {
+		at = t
+	} else if t, err := time.ParseInLocation("2006-01-02", input, now.Location()); err == nil {
+		at = t.Add(8 * time.Hour)
+	} else {
+		return nil, fmt.Errorf("can't read reminder %q; try 2h, 3d, 1w, tomorrow or 2025-07-01 09:00", input)
+	}
+
+	if !at.After(now) {
+		return nil, fmt.Errorf("reminder %q is in the past", input)
+	}
+	return &at, nil
+}
+
+// formatRawPost shows a post's cooked HTML as the server sent it.
+func formatRawPost(post discourse.Post, contentWidth int) string {
+	if contentWidth < 1 {
+		contentWidth = 1
+	}
+	postHeader := fmt.Sprintf("Post #%d by %s (%s) [raw HTML, V to render]",
+		post.PostNumber,
+		post.Name,
+		post.Username)
+	return postHeader + "\n\n" + lipgloss.NewStyle().Width(contentWidth).Render(post.Cooked)
+}
 
-		i++
+// renderLineDiff shows the lines removed from before in red and the lines
+// added in after in green, with unchanged lines in between for context.
+func renderLineDiff(before, after string) string {
+	if before == after {
+		return "No changes to the post body in this revision"
 	}
+	a := strings.Split(before, "\n")
+	b := strings.Split(after, "\n")
 
-	text := result.String()
-	text = strings.ReplaceAll(text, "&lt;", "<")
-	text = strings.ReplaceAll(text, "&gt;", ">")
-	text = strings.ReplaceAll(text, "&amp;", "&")
-	text = strings.ReplaceAll(text, "&quot;", "\"")
-	text = strings.ReplaceAll(text, "&#39;", "'")
+	// Longest common subsequence table, filled from the end.
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	removed := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	added := lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString("  " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString(removed.Render("- "+a[i]) + "\n")
+			i++
+		default:
+			out.WriteString(added.Render("+ "+b[j]) + "\n")
+			j++
+		}
+	}
+	return out.String()
+}
 
-	return text
+func convertHTMLToText(html string) string {
+	return markdown.FromHTML(html, func(text, href string) string {
+		href = cleanHref(href)
+		switch {
+		case cleanHref(text) == href || strings.TrimSpace(text) == "":
+			return href
+		case config.Current.LinkStyle == config.LinkStyleText:
+			return text
+		case config.Current.LinkStyle == config.LinkStyleURL:
+			return href
+		}
+		return fmt.Sprintf("%s (%s)", text, href)
+	})
 }
 
+// Fields of the login form, in tab order. The two-factor field is only
+// added once the forum asks for a code.
+const (
+	loginURLField = iota
+	loginUsernameField
+	loginPasswordField
+	loginProfileField
+	loginSecondFactorField
+)
+
+type loginResultMsg struct{ err error }
+
 type loginModel struct {
 	client         *discourse.Client
 	cookiesPath    string
 	encryptCookies bool
 	inputs         []textinput.Model
 	focusIndex     int
+	busy           string
 	err            error
 	done           bool
 }
 
 func (m loginModel) GetInstanceURL() string {
-	return m.inputs[0].Value()
+	return m.inputs[loginURLField].Value()
 }
 
 func InitialLoginModel(client *discourse.Client, cookiesPath string, encryptCookies bool) loginModel {
@@ -1076,15 +4035,30 @@ func InitialLoginModel(client *discourse.Client, cookiesPath string, encryptCook
 	password.Width = 30
 	password.EchoMode = textinput.EchoPassword
 
+	profile := textinput.New()
+	profile.Placeholder = "Save as profile (optional, e.g. work)"
+	profile.CharLimit = 50
+	profile.Width = 40
+
 	return loginModel{
 		client:         client,
 		cookiesPath:    cookiesPath,
 		encryptCookies: encryptCookies,
-		inputs:         []textinput.Model{url, username, password},
+		inputs:         []textinput.Model{url, username, password, profile},
 		focusIndex:     0,
 	}
 }
 
+// newSecondFactorInput is the code field the login forms add once the forum
+// asks for a two-factor code.
+func newSecondFactorInput() textinput.Model {
+	code := textinput.New()
+	code.Placeholder = "Two-factor code"
+	code.CharLimit = 10
+	code.Width = 20
+	return code
+}
+
 func (m loginModel) Init() tea.Cmd {
 	return textinput.Blink
 }
@@ -1093,13 +4067,32 @@ func (m loginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
+	case loginResultMsg:
+		m.busy = ""
+		if msg.err != nil {
+			if errors.Is(msg.err, discourse.ErrSecondFactorRequired) && len(m.inputs) == loginSecondFactorField {
+				m.inputs[m.focusIndex].Blur()
+				m.inputs = append(m.inputs, newSecondFactorInput())
+				m.focusIndex = loginSecondFactorField
+				m.err = msg.err
+				return m, m.inputs[loginSecondFactorField].Focus()
+			}
+			m.err = msg.err
+			return m, nil
+		}
+		m.done = true
+		return m, tea.Quit
 	case tea.KeyMsg:
+		if m.busy != "" && msg.Type != tea.KeyCtrlC && msg.Type != tea.KeyEsc {
+			return m, nil
+		}
 		switch msg.Type {
 		case tea.KeyEnter:
-			if m.focusIndex == len(m.inputs)-1 {
-				instanceURL := m.inputs[0].Value()
-				username := m.inputs[1].Value()
-				password := m.inputs[2].Value()
+			// The profile name is optional, so the password field submits too
+			if m.focusIndex >= loginPasswordField {
+				instanceURL := m.inputs[loginURLField].Value()
+				username := m.inputs[loginUsernameField].Value()
+				password := m.inputs[loginPasswordField].Value()
 
 				if instanceURL == "" {
 					m.err = fmt.Errorf("instance URL is required")
@@ -1116,20 +4109,23 @@ func (m loginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 				newClient, err := discourse.NewClient(instanceURL, m.cookiesPath, m.encryptCookies)
 				if err != nil {
-					m.err = fmt.Errorf("failed to create client: %v", err)
+					m.err = fmt.Errorf("failed to create client: %w", err)
 					return m, nil
 				}
+				newClient.SetPathPrefix(config.Current.PathPrefix)
+				newClient.SetAcceptLanguage(config.Current.AcceptLanguage)
 				m.client = newClient
 
-				if err := m.client.Login(username, password); err != nil {
-					m.err = fmt.Errorf("login failed: %v", err)
-					return m, nil
+				var token string
+				if len(m.inputs) > loginSecondFactorField {
+					token = m.inputs[loginSecondFactorField].Value()
 				}
-				if err := config.SaveInstance(instanceURL); err != nil {
-					log.Printf("Failed to save instance URL: %v", err)
+				profile := strings.TrimSpace(m.inputs[loginProfileField].Value())
+				m.err = nil
+				m.busy = "Logging in to " + newClient.BaseURL() + "..."
+				return m, func() tea.Msg {
+					return loginResultMsg{err: login(newClient, instanceURL, username, password, token, profile)}
 				}
-				m.done = true
-				return m, tea.Quit
 			} else {
 				m.focusIndex++
 				for i := 0; i < len(m.inputs); i++ {
@@ -1175,6 +4171,46 @@ func (m loginModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// login checks the forum is reachable, logs in to it and saves the session,
+// plus a named profile for it when one was given.
+func login(client *discourse.Client, instanceURL, username, password, token, profile string) error {
+	// A mistyped forum would otherwise fail as a login error
+	if err := client.Ping(); err != nil {
+		if discourse.IsNetworkError(err) {
+			err = fmt.Errorf("could not reach Discourse at %s: %s", client.BaseURL(), discourse.ErrorMessage(err))
+		}
+		return err
+	}
+	if err := client.LoginWithSecondFactor(username, password, token); err != nil {
+		if errors.Is(err, discourse.ErrSecondFactorRequired) {
+			return err
+		}
+		return fmt.Errorf("login failed: %w", err)
+	}
+	saveLogin(client, instanceURL, username)
+	if profile != "" {
+		if err := config.SaveProfile(profile, client.BaseURL()); err != nil {
+			log.Printf("Failed to save profile %s: %v", profile, err)
+		}
+	}
+	return nil
+}
+
+// saveLogin remembers the instance just logged in to and keeps a copy of
+// the session per account so several can be switched between.
+func saveLogin(client *discourse.Client, instanceURL, username string) {
+	if err := config.SaveInstance(instanceURL); err != nil {
+		log.Printf("Failed to save instance URL: %v", err)
+	}
+	// The login name may be an email, so ask who we are
+	if user, err := client.GetCurrentUser(); err == nil {
+		username = user.Username
+	}
+	if err := client.SaveCookies(config.AccountCookiesPath(client.BaseURL(), username)); err != nil {
+		log.Printf("Failed to save cookies for account %s: %v", username, err)
+	}
+}
+
 func (m loginModel) View() string {
 	if m.done {
 		return "Login successful!\n"
@@ -1191,15 +4227,18 @@ func (m loginModel) View() string {
 	}
 
 	s.WriteString("\n\n")
-	if m.focusIndex == len(m.inputs)-1 {
+	if m.focusIndex >= loginPasswordField {
 		s.WriteString(config.SelectedItemStyle.Render("[ Login ]"))
 	} else {
 		s.WriteString(config.ItemStyle.Render("[ Login ]"))
 	}
 
-	if m.err != nil {
+	if m.busy != "" {
+		s.WriteString("\n\n")
+		s.WriteString(config.StatusStyle.Render(m.busy))
+	} else if m.err != nil {
 		s.WriteString("\n\n")
-		s.WriteString(config.ErrorStyle.Render(m.err.Error()))
+		s.WriteString(config.ErrorStyle.Render(discourse.ErrorMessage(m.err)))
 	}
 
 	s.WriteString("\n\nPress Tab/Shift+Tab to switch fields, Enter to submit, Esc to quit") // Updated help text for login
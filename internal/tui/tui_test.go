@@ -0,0 +1,329 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package tui
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"git.quad4.io/discourse-tui-client/pkg/discourse"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestComposerResize(t *testing.T) {
+	tests := []struct {
+		name          string
+		reply         bool
+		width, height int
+		inputWidth    int
+		contentHeight int
+	}{
+		{name: "topic", width: 80, height: 30, inputWidth: 76, contentHeight: 10},
+		{name: "reply", reply: true, width: 80, height: 30, inputWidth: 76, contentHeight: 15},
+		{name: "narrow", width: 12, height: 30, inputWidth: minInputWidth, contentHeight: 10},
+		{name: "short", width: 80, height: 4, inputWidth: 76, contentHeight: minContentHeight},
+		{name: "width 10", width: 10, height: 30, inputWidth: minInputWidth, contentHeight: 10},
+		{name: "width 1", width: 1, height: 30, inputWidth: minInputWidth, contentHeight: 10},
+		{name: "width 0", width: 0, height: 0, inputWidth: minInputWidth, contentHeight: minContentHeight},
+		{name: "reply width 0", reply: true, width: 0, height: 0, inputWidth: minInputWidth, contentHeight: minContentHeight},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := InitialNewTopicModel(nil, tt.width, tt.height)
+			if tt.reply {
+				m = InitialReplyModel(nil, 1, "Topic", nil, tt.width, tt.height)
+			}
+			if got := m.titleInput.Width; !tt.reply && got != tt.inputWidth {
+				t.Errorf("title width = %d, want %d", got, tt.inputWidth)
+			}
+			if got := m.contentInput.Height(); got != tt.contentHeight {
+				t.Errorf("content height = %d, want %d", got, tt.contentHeight)
+			}
+			sizes := map[string]int{
+				"content width":  m.contentInput.Width(),
+				"content height": m.contentInput.Height(),
+			}
+			if !tt.reply {
+				sizes["title width"] = m.titleInput.Width
+				sizes["category width"] = m.categoryInput.Width
+				sizes["tags width"] = m.tagsInput.Width
+			}
+			for name, got := range sizes {
+				if got < 1 {
+					t.Errorf("%s = %d, want at least 1", name, got)
+				}
+			}
+		})
+	}
+}
+
+func TestComposerTooNarrow(t *testing.T) {
+	tests := []struct {
+		width  int
+		narrow bool
+	}{
+		{width: minComposerWidth - 1, narrow: true},
+		{width: minComposerWidth},
+		{width: 80},
+	}
+	for _, tt := range tests {
+		m := InitialNewTopicModel(nil, tt.width, 30)
+		if got := strings.Contains(m.View(), "Widen your terminal"); got != tt.narrow {
+			t.Errorf("width %d: asks for a wider terminal = %t, want %t", tt.width, got, tt.narrow)
+		}
+	}
+}
+
+func TestTopicItemTitle(t *testing.T) {
+	topic := discourse.Topic{Title: "A rather long topic title", CategoryName: "General", Tags: []string{"go", "tui"}}
+	tests := []struct {
+		name  string
+		topic discourse.Topic
+		width int
+		want  string
+	}{
+		{name: "no width", topic: topic, want: "A rather long topic title [General] {go, tui}"},
+		{name: "fits", topic: topic, width: 80, want: "A rather long topic title [General] {go, tui}"},
+		{name: "title shortened", topic: topic, width: 30, want: "A rather … [General] {go, tui}"},
+		{name: "suffix wider than width", topic: topic, width: 10, want: "… [General] {go, tui}"},
+		{name: "no suffix", topic: discourse.Topic{Title: "A rather long topic title"}, width: 10, want: "A rather …"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (topicItem{topic: tt.topic, width: tt.width}).Title(); got != tt.want {
+				t.Errorf("Title() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopicItemTitleVeryLong(t *testing.T) {
+	topic := discourse.Topic{Title: strings.Repeat("word ", 60), CategoryName: "General", Tags: []string{"go", "tui"}}
+	for _, width := range []int{40, 80, 120} {
+		t.Run(fmt.Sprintf("width %d", width), func(t *testing.T) {
+			got := (topicItem{topic: topic, width: width}).Title()
+			if w := lipgloss.Width(got); w > width {
+				t.Errorf("Title() is %d cells wide, want at most %d", w, width)
+			}
+			if !strings.HasSuffix(got, " [General] {go, tui}") {
+				t.Errorf("Title() = %q, want the category and tags kept", got)
+			}
+		})
+	}
+}
+
+func TestRenderPostsOffsets(t *testing.T) {
+	posts := []discourse.Post{
+		{ID: 1, PostNumber: 1, Username: "alice", Cooked: "<p>First</p><p>Second paragraph</p>"},
+		{ID: 2, PostNumber: 2, Username: "bob", Cooked: "<p>" + strings.Repeat("long reply ", 30) + "</p>"},
+		{ID: 3, PostNumber: 3, Username: "carol", Cooked: "<p>Short</p>"},
+	}
+	tests := []struct {
+		name   string
+		cursor int
+		raw    bool
+	}{
+		{name: "first post"},
+		{name: "cursor moved", cursor: 2},
+		{name: "raw post", cursor: 1, raw: true},
+		{name: "back to formatted", cursor: 1},
+	}
+	// One line high, so each offset scrolls its line to the top
+	m := Model{Viewport: viewport.New(40, 1), currentPosts: posts}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m.postCursor = tt.cursor
+			m.rawPosts = map[int]bool{2: tt.raw}
+			m.renderPosts()
+			if len(m.postOffsets) != len(posts) {
+				t.Fatalf("got %d offsets, want %d", len(m.postOffsets), len(posts))
+			}
+			for i, offset := range m.postOffsets {
+				m.Viewport.SetYOffset(offset)
+				if line := m.Viewport.View(); !strings.Contains(line, fmt.Sprintf("#%d ", posts[i].PostNumber)) {
+					t.Errorf("post %d starts at line %d, which is %q", posts[i].PostNumber, offset, line)
+				}
+			}
+		})
+	}
+}
+
+func TestJoinLines(t *testing.T) {
+	tests := []struct {
+		name  string
+		paste string
+		want  string
+	}{
+		{name: "single line", paste: "Hello world", want: "Hello world"},
+		{name: "inner spacing kept", paste: "a  b\tc", want: "a  b\tc"},
+		{name: "lines joined", paste: "first line\nsecond line", want: "first line second line"},
+		{name: "lines trimmed", paste: "  first \n\tsecond  ", want: "first second"},
+		{name: "blank lines dropped", paste: "first\n\n \nsecond\n", want: "first second"},
+		{name: "empty", paste: "\n\n", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := joinLines(tt.paste); got != tt.want {
+				t.Errorf("joinLines(%q) = %q, want %q", tt.paste, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCooldownTickInAnyState(t *testing.T) {
+	tests := []struct {
+		name  string
+		state modelState
+	}{
+		{name: "topic list", state: stateTopicList},
+		{name: "overlay", state: stateOverlay},
+		{name: "composer", state: stateNewTopic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Model{State: tt.state, StatusMessage: "Loading all topics", cooldownTicking: true, coolingDown: true}
+			updated, _ := m.Update(cooldownTickMsg{})
+			got := updated.(Model)
+			if got.cooldownTicking || got.coolingDown {
+				t.Errorf("cooldownTicking = %t, coolingDown = %t after the fetch ended; want both false", got.cooldownTicking, got.coolingDown)
+			}
+			if got.StatusMessage != m.StatusMessage {
+				t.Errorf("StatusMessage = %q, want %q kept", got.StatusMessage, m.StatusMessage)
+			}
+		})
+	}
+}
+
+func TestRateLimitTickKeepsStatus(t *testing.T) {
+	m := testModel(t, nil)
+	m.StatusMessage = "Copied link to topic"
+	m.rateLimitTicking = true
+	updated, _ := m.Update(rateLimitTickMsg{})
+	got := updated.(Model)
+	if got.rateLimitTicking {
+		t.Error("rateLimitTicking still set once the client is no longer rate limited")
+	}
+	if got.StatusMessage != m.StatusMessage {
+		t.Errorf("StatusMessage = %q, want %q kept", got.StatusMessage, m.StatusMessage)
+	}
+}
+
+// testModel returns a model for topics with a client that is never asked
+// to make requests.
+func testModel(t *testing.T, topics []discourse.Topic) Model {
+	t.Helper()
+	client, err := discourse.NewClientWithHTTPClient("forum.example.com", "", false, &http.Client{})
+	if err != nil {
+		t.Fatalf("NewClientWithHTTPClient: %v", err)
+	}
+	return InitialModel(client, topics)
+}
+
+func TestTopicMarkedReadInAnyState(t *testing.T) {
+	tests := []struct {
+		name  string
+		state modelState
+	}{
+		{name: "topic list", state: stateTopicList},
+		{name: "overlay", state: stateOverlay},
+		{name: "composer", state: stateNewTopic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel(t, []discourse.Topic{{ID: 7, Title: "Topic", HighestPostNumber: 10, LastReadPostNumber: 2, Unread: 8, UnreadPosts: 8}})
+			m.State = tt.state
+			updated, _ := m.Update(topicMarkedReadMsg{topicID: 7, postNumber: 6})
+			topic := updated.(Model).Topics[0]
+			if topic.LastReadPostNumber != 6 || topic.UnreadPosts != 4 {
+				t.Errorf("LastReadPostNumber = %d, UnreadPosts = %d; want 6, 4", topic.LastReadPostNumber, topic.UnreadPosts)
+			}
+		})
+	}
+}
+
+func TestLoadAllTopicsInAnyState(t *testing.T) {
+	tests := []struct {
+		name  string
+		state modelState
+		msg   tea.Msg
+	}{
+		{name: "loaded in overlay", state: stateOverlay, msg: loadAllTopicsMsg{response: &discourse.Response{}}},
+		{name: "loaded in composer", state: stateNewTopic, msg: loadAllTopicsMsg{response: &discourse.Response{}}},
+		{name: "failed in overlay", state: stateOverlay, msg: loadAllTopicsErrorMsg{err: context.Canceled}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel(t, nil)
+			m.State = tt.state
+			m.isLoadingAll = true
+			m.loadAllCancel = func() {}
+			updated, _ := m.Update(tt.msg)
+			got := updated.(Model)
+			if got.isLoadingAll || got.loadAllCancel != nil {
+				t.Errorf("isLoadingAll = %t, loadAllCancel set = %t; want the load finished", got.isLoadingAll, got.loadAllCancel != nil)
+			}
+		})
+	}
+}
+
+func TestMoreTopicsInAnyState(t *testing.T) {
+	tests := []struct {
+		name  string
+		state modelState
+		msg   tea.Msg
+	}{
+		{name: "loaded in overlay", state: stateOverlay, msg: moreTopicsLoadedMsg{response: &discourse.Response{}, view: viewLatest}},
+		{name: "loaded in composer", state: stateNewTopic, msg: moreTopicsLoadedMsg{response: &discourse.Response{}, view: viewLatest}},
+		{name: "failed in overlay", state: stateOverlay, msg: moreTopicsLoadErrorMsg{err: context.DeadlineExceeded}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := testModel(t, nil)
+			m.State = tt.state
+			m.isLoadingMore = true
+			updated, _ := m.Update(tt.msg)
+			if updated.(Model).isLoadingMore {
+				t.Error("isLoadingMore still set after the page load finished")
+			}
+		})
+	}
+}
+
+func TestPostsWindowReplaced(t *testing.T) {
+	posts := func(from, to int) *discourse.TopicResponse {
+		response := &discourse.TopicResponse{}
+		for n := from; n <= to; n++ {
+			response.PostStream.Posts = append(response.PostStream.Posts, discourse.Post{ID: n, TopicID: 7, PostNumber: n, Username: "alice", Cooked: "<p>Post</p>"})
+		}
+		return response
+	}
+	next := func() tea.Msg { return nil }
+
+	m := testModel(t, nil)
+	m.Viewport = viewport.New(40, 10)
+	m.currentTopicID = 7
+	m.jumpToPost = 45
+	m.postsLoaded(postsLoadedMsg{topicID: 7, posts: posts(40, 65), window: true})
+	if got := m.currentPosts[m.postCursor].PostNumber; got != 45 {
+		t.Fatalf("window opened on post %d, want 45", got)
+	}
+
+	m.postsLoaded(postsLoadedMsg{topicID: 7, posts: posts(1, 20), next: next})
+	if got := m.currentPosts[0].PostNumber; got != 40 {
+		t.Errorf("chunk ending before the window replaced it; first post shown = %d", got)
+	}
+
+	m.postsLoaded(postsLoadedMsg{topicID: 7, posts: posts(1, 100), next: next})
+	if m.postsWindow {
+		t.Error("postsWindow still set once a chunk covered the window")
+	}
+	if got := m.currentPosts[m.postCursor].PostNumber; got != 45 {
+		t.Errorf("cursor on post %d after the window was replaced, want 45", got)
+	}
+}
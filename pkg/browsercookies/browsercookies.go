@@ -0,0 +1,179 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+// Package browsercookies reads a forum's session cookies out of a locally
+// installed browser so users don't have to export them by hand.
+package browsercookies
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// ErrLocked is returned when the browser holds a lock on its cookie store,
+// which Firefox does for as long as it is running.
+var ErrLocked = errors.New("the browser's cookie store is locked; close the browser and try again")
+
+var hostPattern = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+
+// Import returns the cookies the named browser holds for host and its parent
+// domains. Only "firefox" is supported so far.
+func Import(browser, host string) ([]*http.Cookie, error) {
+	if !hostPattern.MatchString(host) {
+		return nil, fmt.Errorf("invalid host %q", host)
+	}
+	switch strings.ToLower(browser) {
+	case "firefox":
+		return importFirefox(host)
+	case "chrome", "chromium":
+		return nil, fmt.Errorf("importing from %s is not supported yet (its cookies are encrypted); export them manually or use firefox", browser)
+	default:
+		return nil, fmt.Errorf("unknown browser %q (supported: firefox)", browser)
+	}
+}
+
+func firefoxRoots() []string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil
+	}
+	switch runtime.GOOS {
+	case "darwin":
+		return []string{filepath.Join(home, "Library", "Application Support", "Firefox")}
+	case "windows":
+		return []string{filepath.Join(os.Getenv("APPDATA"), "Mozilla", "Firefox")}
+	default:
+		return []string{
+			filepath.Join(home, ".mozilla", "firefox"),
+			filepath.Join(home, "snap", "firefox", "common", ".mozilla", "firefox"),
+			filepath.Join(home, ".var", "app", "org.mozilla.firefox", ".mozilla", "firefox"),
+		}
+	}
+}
+
+// firefoxProfile finds the default profile directory via profiles.ini,
+// preferring the profile the installation marks as its default.
+func firefoxProfile() (string, error) {
+	for _, root := range firefoxRoots() {
+		/* #nosec G304 */
+		data, err := os.ReadFile(filepath.Join(root, "profiles.ini"))
+		if err != nil {
+			continue
+		}
+
+		var installDefault, markedDefault, first string
+		var section, path string
+		relative, isDefault := true, false
+		flush := func() {
+			if !strings.HasPrefix(section, "Profile") || path == "" {
+				return
+			}
+			if relative {
+				path = filepath.Join(root, path)
+			}
+			if first == "" {
+				first = path
+			}
+			if isDefault && markedDefault == "" {
+				markedDefault = path
+			}
+		}
+
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+				flush()
+				section = strings.Trim(line, "[]")
+				path, relative, isDefault = "", true, false
+				continue
+			}
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				continue
+			}
+			switch {
+			case strings.HasPrefix(section, "Install") && key == "Default" && installDefault == "":
+				installDefault = filepath.Join(root, value)
+			case key == "Path":
+				path = value
+			case key == "IsRelative":
+				relative = value == "1"
+			case key == "Default":
+				isDefault = value == "1"
+			}
+		}
+		flush()
+
+		for _, candidate := range []string{installDefault, markedDefault, first} {
+			if candidate == "" {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(candidate, "cookies.sqlite")); err == nil {
+				return candidate, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no Firefox profile with a cookie store found")
+}
+
+func importFirefox(host string) ([]*http.Cookie, error) {
+	profile, err := firefoxProfile()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := exec.LookPath("sqlite3"); err != nil {
+		return nil, fmt.Errorf("the sqlite3 command is needed to read Firefox cookies: %w", err)
+	}
+
+	// Match the host itself, even a single-label one like localhost, and
+	// every parent domain short of the TLD a cookie could be scoped to.
+	var hosts []string
+	if host != "" {
+		hosts = append(hosts, "'"+host+"'", "'."+host+"'")
+	}
+	labels := strings.Split(host, ".")
+	for i := 1; i < len(labels)-1; i++ {
+		domain := strings.Join(labels[i:], ".")
+		hosts = append(hosts, "'"+domain+"'", "'."+domain+"'")
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no host to look up Firefox cookies for")
+	}
+	query := fmt.Sprintf("SELECT name, value FROM moz_cookies WHERE host IN (%s);", strings.Join(hosts, ","))
+
+	dbPath := filepath.Join(profile, "cookies.sqlite")
+	/* #nosec G204 */
+	cmd := exec.Command("sqlite3", "-readonly", "-separator", "\t", dbPath, query)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if strings.Contains(stderr.String(), "locked") {
+			return nil, ErrLocked
+		}
+		return nil, fmt.Errorf("failed to read %s: %v: %s", dbPath, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var cookies []*http.Cookie
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		name, value, ok := strings.Cut(line, "\t")
+		if !ok || name == "" {
+			continue
+		}
+		cookies = append(cookies, &http.Cookie{Name: name, Value: value})
+	}
+	if len(cookies) == 0 {
+		return nil, fmt.Errorf("no Firefox cookies found for %s; log in there first", host)
+	}
+	return cookies, nil
+}
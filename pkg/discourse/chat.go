@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package discourse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/tidwall/gjson"
+)
+
+// ChatChannel is a channel of the Discourse chat plugin, either a public
+// channel or a direct message conversation.
+type ChatChannel struct {
+	ID            int    `json:"id"`
+	Title         string `json:"title"`
+	Slug          string `json:"slug"`
+	Description   string `json:"description"`
+	DirectMessage bool   `json:"-"`
+}
+
+type ChatMessage struct {
+	ID        int       `json:"id"`
+	Username  string    `json:"username"`
+	Message   string    `json:"message"`
+	Cooked    string    `json:"cooked"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DefaultChatPageSize is the number of messages GetChatMessages asks for.
+const DefaultChatPageSize = 50
+
+// ErrChatUnavailable is returned by the chat calls on forums without the
+// chat plugin, or with chat turned off.
+var ErrChatUnavailable = errors.New("this forum doesn't have chat enabled")
+
+// GetChatChannels returns the public channels followed by the direct message
+// channels of the user.
+func (c *Client) GetChatChannels() ([]ChatChannel, error) {
+	body, err := c.getChat("/chat/api/channels.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chat channels: %w", err)
+	}
+
+	var channels []ChatChannel
+	add := func(path string, direct bool) {
+		gjson.GetBytes(body, path).ForEach(func(_, value gjson.Result) bool {
+			channels = append(channels, ChatChannel{
+				ID:            int(value.Get("id").Int()),
+				Title:         value.Get("title").Str,
+				Slug:          value.Get("slug").Str,
+				Description:   value.Get("description").Str,
+				DirectMessage: direct,
+			})
+			return true
+		})
+	}
+	add("public_channels", false)
+	add("direct_message_channels", true)
+	return channels, nil
+}
+
+// GetChatMessages returns the latest messages of a channel, oldest first.
+func (c *Client) GetChatMessages(channelID int) ([]ChatMessage, error) {
+	body, err := c.getChat(fmt.Sprintf("/chat/api/channels/%d/messages.json?page_size=%d", channelID, DefaultChatPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chat messages: %w", err)
+	}
+
+	var messages []ChatMessage
+	gjson.GetBytes(body, "messages").ForEach(func(_, value gjson.Result) bool {
+		messages = append(messages, ChatMessage{
+			ID:        int(value.Get("id").Int()),
+			Username:  value.Get("user.username").Str,
+			Message:   value.Get("message").Str,
+			Cooked:    value.Get("cooked").Str,
+			CreatedAt: value.Get("created_at").Time(),
+		})
+		return true
+	})
+	return messages, nil
+}
+
+// getChat fetches a chat API endpoint, turning the 404 of forums without
+// chat into ErrChatUnavailable.
+func (c *Client) getChat(path string) ([]byte, error) {
+	resp, err := c.get(c.endpoint(path))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrChatUnavailable
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("chat API error: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chat response body: %w", err)
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("invalid JSON response from server")
+	}
+	return body, nil
+}
@@ -5,8 +5,11 @@ package discourse
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html"
 	"io"
 	"log"
 	"net/http"
@@ -14,11 +17,17 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"git.quad4.io/discourse-tui-client/pkg/crypto"
 	"github.com/tidwall/gjson"
+	"golang.org/x/net/idna"
 )
 
 
@@ -51,6 +60,8 @@ type Topic struct {
 	NewPosts           int       `json:"new_posts"`
 	UnreadPosts        int       `json:"unread_posts"`
 	Pinned             bool      `json:"pinned"`
+	PinnedGlobally     bool      `json:"pinned_globally"`
+	HasAcceptedAnswer  bool      `json:"has_accepted_answer,omitempty"` // discourse-solved plugin
 	Unpinned           *bool     `json:"unpinned"`
 	Visible            bool      `json:"visible"`
 	Closed             bool      `json:"closed"`
@@ -65,6 +76,45 @@ type Topic struct {
 	CategoryID         int       `json:"category_id"`
 	CategoryName       string    `json:"category_name"`
 	CategoryColor      string    `json:"category_color"`
+	Posters            []Poster  `json:"posters"`
+	ParticipantCount   int       `json:"participant_count,omitempty"` // only from GetTopic
+	// CreatorUsername is resolved from Posters and the list's users.
+	CreatorUsername string `json:"creator_username"`
+}
+
+// Poster is an entry of a topic's posters summary in topic lists.
+type Poster struct {
+	UserID      int    `json:"user_id"`
+	Description string `json:"description"`
+}
+
+// IsPinned reports whether the topic is pinned and the user hasn't
+// dismissed the pin.
+func (t Topic) IsPinned() bool {
+	return t.Pinned && (t.Unpinned == nil || !*t.Unpinned)
+}
+
+// IsPrivateMessage reports whether the topic is a personal message rather
+// than a regular topic.
+func (t Topic) IsPrivateMessage() bool {
+	return t.Archetype == "private_message"
+}
+
+// HasUnread reports whether the user is tracking the topic and has posts in
+// it left to read.
+func (t Topic) HasUnread() bool {
+	return t.LastReadPostNumber > 0 && (t.UnreadPosts > 0 || t.NewPosts > 0 || t.Unread > 0)
+}
+
+// Replies returns the number of replies as Discourse's web UI counts them:
+// every post after the first. ReplyCount only counts posts made with the
+// reply-to-post button, so it is used only when PostsCount is unknown (as
+// for topics built from search results).
+func (t Topic) Replies() int {
+	if t.PostsCount == 0 {
+		return t.ReplyCount
+	}
+	return t.PostsCount - 1
 }
 
 type TopicList struct {
@@ -95,6 +145,48 @@ type Post struct {
 	Reads          int              `json:"reads"`
 	Score          float64          `json:"score"`
 	ActionsSummary []ActionsSummary `json:"actions_summary,omitempty"`
+	// Version is 1 for a post that has never been edited.
+	Version            int    `json:"version"`
+	CanViewEditHistory bool   `json:"can_view_edit_history"`
+	PostType           int    `json:"post_type"`
+	ReplyToPostNumber  int    `json:"reply_to_post_number,omitempty"`
+	ActionCode         string `json:"action_code,omitempty"`
+	AcceptedAnswer     bool   `json:"accepted_answer,omitempty"` // discourse-solved plugin
+}
+
+// Post types as Discourse numbers them.
+const (
+	PostTypeRegular         = 1
+	PostTypeModeratorAction = 2
+	PostTypeSmallAction     = 3
+	PostTypeWhisper         = 4
+)
+
+// IsWhisper reports whether the post is a staff whisper.
+func (p Post) IsWhisper() bool {
+	return p.PostType == PostTypeWhisper
+}
+
+// IsNotice reports whether the post is a moderator notice or a small action
+// such as "closed" or "pinned" rather than a normal post.
+func (p Post) IsNotice() bool {
+	return p.PostType == PostTypeModeratorAction || p.PostType == PostTypeSmallAction
+}
+
+// Edited reports whether the post has been changed since it was created.
+func (p Post) Edited() bool {
+	return p.Version > 1
+}
+
+// Revision is one edit of a post, as shown by Discourse's edit history.
+type Revision struct {
+	PostID           int       `json:"post_id"`
+	CurrentRevision  int       `json:"current_revision"`
+	PreviousRevision int       `json:"previous_revision"`
+	Username         string    `json:"username"`
+	CreatedAt        time.Time `json:"created_at"`
+	Before           string    `json:"-"`
+	After            string    `json:"-"`
 }
 
 type PostStream struct {
@@ -113,6 +205,9 @@ type ActionsSummary struct {
 	CanUndo bool `json:"can_undo"`
 }
 
+// PostActionLike is the post action type of a like.
+const PostActionLike = 2
+
 type Category struct {
 	ID          int    `json:"id"`
 	Name        string `json:"name"`
@@ -123,6 +218,21 @@ type Category struct {
 	PostCount   int    `json:"post_count"`
 	Position    int    `json:"position"`
 	Description string `json:"description"`
+	// SubcategoryIDs lists children that categories.json doesn't expand.
+	SubcategoryIDs      []int              `json:"subcategory_ids"`
+	MinimumRequiredTags int                `json:"minimum_required_tags"`
+	RequiredTagGroups   []RequiredTagGroup `json:"required_tag_groups"`
+	// Permission is the current user's access level; 0 when not logged in.
+	Permission int `json:"permission"`
+}
+
+// CategoryPermissionFull is the Permission that allows creating topics;
+// 2 only allows replying and 3 is read-only.
+const CategoryPermissionFull = 1
+
+type RequiredTagGroup struct {
+	Name     string `json:"name"`
+	MinCount int    `json:"min_count"`
 }
 
 type CategoryList struct {
@@ -156,6 +266,60 @@ type SearchResponse struct {
 	Category []interface{}  `json:"categories"`
 }
 
+type UserProfile struct {
+	ID             int       `json:"id"`
+	Username       string    `json:"username"`
+	Name           string    `json:"name"`
+	AvatarTemplate string    `json:"avatar_template"`
+	TrustLevel     int       `json:"trust_level"`
+	Moderator      bool      `json:"moderator"`
+	Admin          bool      `json:"admin"`
+	Bio            string    `json:"bio_raw"`
+	BioExcerpt     string    `json:"bio_excerpt"`
+	CreatedAt      time.Time `json:"created_at"`
+	PostCount      int       `json:"post_count"`
+	BadgeCount     int       `json:"badge_count"`
+	// Follow plugin fields; FollowSupported is false on instances without it.
+	FollowSupported bool `json:"-"`
+	CanFollow       bool `json:"can_follow"`
+	IsFollowed      bool `json:"is_followed"`
+	TotalFollowers  int  `json:"total_followers"`
+	TotalFollowing  int  `json:"total_following"`
+}
+
+// UserAction is an entry of a user's activity stream: a topic they started
+// or a reply they posted.
+type UserAction struct {
+	ActionType int       `json:"action_type"`
+	TopicID    int       `json:"topic_id"`
+	PostID     int       `json:"post_id"`
+	PostNumber int       `json:"post_number"`
+	Title      string    `json:"title"`
+	Slug       string    `json:"slug"`
+	Excerpt    string    `json:"excerpt"`
+	CategoryID int       `json:"category_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Notification is an entry of the current user's notifications. TopicID and
+// PostNumber are zero for notifications not about a post, such as badges.
+type Notification struct {
+	ID               int       `json:"id"`
+	NotificationType int       `json:"notification_type"`
+	Read             bool      `json:"read"`
+	CreatedAt        time.Time `json:"created_at"`
+	TopicID          int       `json:"topic_id"`
+	PostNumber       int       `json:"post_number"`
+	Title            string    `json:"fancy_title"`
+	Username         string    `json:"-"`
+}
+
+// User action types of the activity stream.
+const (
+	UserActionNewTopic = 4
+	UserActionReply    = 5
+)
+
 type apiCreateTopicPayload struct {
 	Title     string   `json:"title"`
 	Raw       string   `json:"raw"`
@@ -164,16 +328,120 @@ type apiCreateTopicPayload struct {
 	Archetype string   `json:"archetype"`
 }
 
+type apiUpdateTopicPayload struct {
+	Title      string   `json:"title"`
+	CategoryID int      `json:"category_id,omitempty"`
+	Tags       []string `json:"tags"`
+}
+
+type apiCreatePostPayload struct {
+	TopicID           int    `json:"topic_id"`
+	Raw               string `json:"raw"`
+	ReplyToPostNumber int    `json:"reply_to_post_number,omitempty"`
+}
+
 type Client struct {
 	client         *http.Client
+	transport      *http.Transport
+	jar            *switchableJar
 	baseURL        string
-	cookiesPath    string
 	pageCooldown   time.Duration
+	loadAllTimeout time.Duration
+	postBatchSize  int
+	maxRetries     int
 	encryptCookies bool
 	cookiePassword string
+	pathPrefix     string
+	acceptLanguage string
+	apiKey         string
+	apiUsername    string
+	coolingDown    atomic.Bool
+
+	rateMu           sync.Mutex
+	rateLimitHits    int
+	rateLimitedUntil time.Time
+
+	// sessionMu guards cookiesPath, which SwitchCookies changes while
+	// requests may be running.
+	sessionMu   sync.RWMutex
+	cookiesPath string
+}
+
+// switchableJar is the client's cookie jar. SwitchCookies replaces the jar
+// behind it rather than http.Client.Jar, which requests in flight read.
+type switchableJar struct {
+	mu  sync.RWMutex
+	jar http.CookieJar
+}
+
+func (j *switchableJar) current() http.CookieJar {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return j.jar
+}
+
+func (j *switchableJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	j.current().SetCookies(u, cookies)
+}
+
+func (j *switchableJar) Cookies(u *url.URL) []*http.Cookie {
+	return j.current().Cookies(u)
+}
+
+func (j *switchableJar) swap(jar http.CookieJar) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.jar = jar
 }
 
+// ErrSSORequired is returned when the instance redirects API requests to its
+// SSO login page instead of answering them.
+var ErrSSORequired = errors.New("instance redirected to SSO login; log in through the browser and import the session cookie")
+
+// ErrLoginRequired is returned when a login_required instance redirects a
+// request to its login page, typically because the session expired.
+var ErrLoginRequired = errors.New("the forum requires logging in")
+
+// ErrSessionExpired is returned when a request sent with session cookies is
+// answered as if logged out: a redirect to the login page, a not_logged_in
+// 403, or an HTML page where JSON was asked for. GetCurrentUser also returns
+// it when there is no current user.
+var ErrSessionExpired = errors.New("your session has expired; log in again")
+
+// ErrSecondFactorRequired is returned by Login for accounts with two-factor
+// authentication; log in again with LoginWithSecondFactor.
+var ErrSecondFactorRequired = errors.New("this account uses two-factor authentication; enter the code from your authenticator app")
+
+// ErrRateLimited is returned for responses with status 429 Too Many Requests.
+var ErrRateLimited = errors.New("rate limited by the forum")
+
+const (
+	// rateLimitThreshold consecutive 429s pause background requests.
+	rateLimitThreshold    = 3
+	defaultRateLimitPause = time.Minute
+	maxRateLimitPause     = 15 * time.Minute
+	// maxRetryWait caps how long a rate limited request waits to be retried;
+	// a longer Retry-After fails it with ErrRateLimited right away.
+	maxRetryWait = time.Minute
+)
+
+// DefaultMaxRetries is how many times a rate limited request is retried.
+const DefaultMaxRetries = 3
+
+// ErrHotUnavailable is returned by GetHotTopics on forums too old to have
+// the hot topics view.
+var ErrHotUnavailable = errors.New("this forum has no hot topics view (it needs a newer Discourse); try /top for its most active topics instead")
+
+// ErrActivityHidden is returned by GetUserActivity for users who hide their
+// profile and activity.
+var ErrActivityHidden = errors.New("this user has hidden their profile and activity")
+
+// ErrUserNotFound is returned by GetUser for deleted or anonymized users.
+var ErrUserNotFound = errors.New("this user doesn't exist or has been deleted")
+
 func (c *Client) CookiesPath() string {
+	c.sessionMu.RLock()
+	defer c.sessionMu.RUnlock()
 	return c.cookiesPath
 }
 
@@ -181,34 +449,322 @@ func (c *Client) BaseURL() string {
 	return c.baseURL
 }
 
+// SetPathPrefix sets a path inserted between the base URL and every endpoint,
+// for instances served from a subfolder or behind a locale prefix ("/forum", "/en").
+func (c *Client) SetPathPrefix(prefix string) {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		c.pathPrefix = ""
+		return
+	}
+	c.pathPrefix = "/" + prefix
+}
+
+// SetAcceptLanguage sets the Accept-Language header sent with every request.
+func (c *Client) SetAcceptLanguage(lang string) {
+	c.acceptLanguage = lang
+}
+
+// SetAPIKey authenticates every request with an API key acting as
+// apiUsername instead of session cookies.
+func (c *Client) SetAPIKey(apiKey, apiUsername string) {
+	c.apiKey = apiKey
+	c.apiUsername = apiUsername
+}
+
+func (c *Client) endpoint(path string) string {
+	return c.baseURL + c.pathPrefix + path
+}
+
+// TopicURL returns the canonical web address of a topic.
+func (c *Client) TopicURL(topic Topic) string {
+	if topic.Slug == "" {
+		return c.endpoint(fmt.Sprintf("/t/%d", topic.ID))
+	}
+	return c.endpoint(fmt.Sprintf("/t/%s/%d", topic.Slug, topic.ID))
+}
+
+// PostURL returns the permalink of a post within its topic.
+func (c *Client) PostURL(post Post) string {
+	return fmt.Sprintf("%s/%d", c.TopicURL(Topic{ID: post.TopicID, Slug: post.TopicSlug}), post.PostNumber)
+}
+
+// doRequest sends req with the client's shared headers. All API calls go
+// through here.
+func (c *Client) doRequest(req *http.Request) (*http.Response, error) {
+	if c.acceptLanguage != "" && req.Header.Get("Accept-Language") == "" {
+		req.Header.Set("Accept-Language", c.acceptLanguage)
+	}
+	// The web client sends these on every request; some proxies and
+	// plugins serve anonymised content without them.
+	req.Header.Set("Discourse-Present", "true")
+	if c.apiKey != "" {
+		req.Header.Set("Api-Key", c.apiKey)
+		req.Header.Set("Api-Username", c.apiUsername)
+	}
+	// Checked before sending, as an expired session's cookie is cleared by
+	// the response
+	hadSession := c.hasSessionCookie(req.URL)
+	if c.apiKey != "" || hadSession {
+		req.Header.Set("Discourse-Logged-In", "true")
+	}
+	resp, err := c.client.Do(req)
+	for attempt := 0; err == nil && resp.StatusCode == http.StatusTooManyRequests; attempt++ {
+		c.noteRateLimit(resp)
+		resp.Body.Close()
+		wait, ok := c.retryWait(req, resp, attempt)
+		if !ok {
+			return nil, ErrRateLimited
+		}
+		log.Printf("Rate limited on %s; retrying in %s (%d/%d)", req.URL.Path, wait, attempt+1, c.maxRetries)
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		resp, err = c.client.Do(req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.noteRateLimit(resp)
+	if isSSORedirect(resp) {
+		resp.Body.Close()
+		return nil, ErrSSORequired
+	}
+	if hadSession && c.sessionRejected(resp) {
+		resp.Body.Close()
+		return nil, ErrSessionExpired
+	}
+	if c.isLoginRedirect(resp) {
+		resp.Body.Close()
+		return nil, ErrLoginRequired
+	}
+	return resp, nil
+}
+
+// sessionRejected reports whether resp treats a request that carried session
+// cookies as anonymous. The body of a 403 is read to check, and put back.
+func (c *Client) sessionRejected(resp *http.Response) bool {
+	if c.isLoginRedirect(resp) {
+		return true
+	}
+	switch {
+	case resp.StatusCode == http.StatusForbidden:
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+		return err == nil && gjson.GetBytes(body, "error_type").Str == "not_logged_in"
+	case resp.StatusCode == http.StatusOK && resp.Request != nil && strings.HasSuffix(resp.Request.URL.Path, ".json"):
+		contentType := resp.Header.Get("Content-Type")
+		return strings.Contains(contentType, "text/html")
+	}
+	return false
+}
+
+// noteRateLimit trips the rate limit breaker after rateLimitThreshold 429s in
+// a row, pausing background requests for the server's Retry-After (or a
+// minute). Any successful response resets it.
+func (c *Client) noteRateLimit(resp *http.Response) {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		if resp.StatusCode < 400 {
+			c.rateLimitHits = 0
+			c.rateLimitedUntil = time.Time{}
+		}
+		return
+	}
+	c.rateLimitHits++
+	if c.rateLimitHits < rateLimitThreshold {
+		return
+	}
+	pause, ok := retryAfter(resp)
+	if !ok {
+		pause = defaultRateLimitPause
+	}
+	pause = min(max(pause, time.Second), maxRateLimitPause)
+	c.rateLimitedUntil = time.Now().Add(pause)
+	log.Printf("Rate limited %d times in a row; pausing background requests for %s", c.rateLimitHits, pause)
+}
+
+// retryAfter returns the wait a 429 response asks for in its Retry-After
+// header, given either in seconds or as a date.
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at), true
+	}
+	return 0, false
+}
+
+// retryWait returns how long to wait before retrying a rate limited request
+// for the attempt+1th time: the server's Retry-After, or an exponential
+// backoff from a second. It reports false when the request shouldn't be
+// retried: it ran out of retries, its body can't be sent again, or the
+// server asked for a longer wait than maxRetryWait.
+func (c *Client) retryWait(req *http.Request, resp *http.Response, attempt int) (time.Duration, bool) {
+	if attempt >= c.maxRetries || (req.Body != nil && req.GetBody == nil) {
+		return 0, false
+	}
+	wait, ok := retryAfter(resp)
+	if !ok {
+		wait = time.Second << attempt
+	}
+	if wait > maxRetryWait {
+		return 0, false
+	}
+	return max(wait, 0), true
+}
+
+// RateLimitedFor returns how much longer background requests should stay
+// paused after repeated 429 responses, or 0.
+func (c *Client) RateLimitedFor() time.Duration {
+	c.rateMu.Lock()
+	defer c.rateMu.Unlock()
+	return max(time.Until(c.rateLimitedUntil), 0)
+}
+
+// SwitchCookies replaces the session with the cookies saved at cookiesPath,
+// e.g. to change to another account on the same instance. The current
+// session is kept if they can't be loaded.
+func (c *Client) SwitchCookies(cookiesPath string) error {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return fmt.Errorf("failed to create cookie jar: %w", err)
+	}
+	cookies, err := c.readCookies(cookiesPath)
+	if err != nil {
+		return err
+	}
+	parsedURL, err := url.Parse(c.baseURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse base URL: %w", err)
+	}
+	jar.SetCookies(parsedURL, cookies)
+	c.jar.swap(jar)
+
+	c.sessionMu.Lock()
+	c.cookiesPath = cookiesPath
+	c.sessionMu.Unlock()
+	return nil
+}
+
+// hasSessionCookie reports whether the jar holds a login token for u.
+func (c *Client) hasSessionCookie(u *url.URL) bool {
+	for _, cookie := range c.client.Jar.Cookies(u) {
+		if cookie.Name == "_t" && cookie.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) get(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.doRequest(req)
+}
+
+// isSSORedirect reports whether resp is the HTML SSO login page we were
+// redirected to rather than the JSON we asked for.
+func isSSORedirect(resp *http.Response) bool {
+	if resp.Request == nil || resp.Request.URL == nil {
+		return false
+	}
+	if !strings.Contains(resp.Request.URL.Path, "/session/sso") {
+		return false
+	}
+	return !strings.Contains(resp.Header.Get("Content-Type"), "json")
+}
+
+// isLoginRedirect reports whether resp is the HTML login page we were
+// redirected to instead of the requested resource.
+func (c *Client) isLoginRedirect(resp *http.Response) bool {
+	if resp.Request == nil || resp.Request.URL == nil || resp.Request.Response == nil {
+		return false
+	}
+	if resp.Request.URL.Path != c.pathPrefix+"/login" {
+		return false
+	}
+	return !strings.Contains(resp.Header.Get("Content-Type"), "json")
+}
+
+// DefaultTimeout bounds each request of a client made by NewClient.
+const DefaultTimeout = 10 * time.Second
+
 func NewClient(baseURL string, cookiesPath string, encryptCookies bool) (*Client, error) {
+	return NewClientWithHTTPClient(baseURL, cookiesPath, encryptCookies, &http.Client{
+		Transport: newTransport(),
+		Timeout:   DefaultTimeout,
+	})
+}
+
+// NewClientWithHTTPClient is like NewClient but sends all requests through the
+// given http.Client, e.g. one pointed at an httptest.Server. A cookie jar is
+// added if the client doesn't have one.
+func NewClientWithHTTPClient(baseURL string, cookiesPath string, encryptCookies bool, httpClient *http.Client) (*Client, error) {
 	if baseURL == "" {
 		return nil, fmt.Errorf("baseURL is required")
 	}
+	if httpClient == nil {
+		return nil, fmt.Errorf("http client is required")
+	}
 
+	baseURL = strings.TrimSpace(baseURL)
 	if !strings.HasPrefix(baseURL, "http://") && !strings.HasPrefix(baseURL, "https://") {
 		baseURL = "https://" + baseURL
 	}
 
 	baseURL = strings.TrimSuffix(baseURL, "/")
-
-	jar, err := cookiejar.New(nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create cookie jar: %v", err)
+	if err := validateBaseURL(baseURL); err != nil {
+		return nil, err
 	}
 
-	client := &http.Client{
-		Jar:     jar,
-		Timeout: 10 * time.Second,
+	if httpClient.Jar == nil {
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create cookie jar: %w", err)
+		}
+		httpClient.Jar = jar
+	}
+	jar, ok := httpClient.Jar.(*switchableJar)
+	if !ok {
+		jar = &switchableJar{jar: httpClient.Jar}
+		httpClient.Jar = jar
 	}
 
-	return &Client{
-		client:         client,
+	c := &Client{
+		client:         httpClient,
+		jar:            jar,
 		baseURL:        baseURL,
 		cookiesPath:    cookiesPath,
 		pageCooldown:   500 * time.Millisecond,
+		postBatchSize:  DefaultPostBatchSize,
+		maxRetries:     DefaultMaxRetries,
 		encryptCookies: encryptCookies,
-	}, nil
+	}
+	if t, ok := httpClient.Transport.(*tlsTransport); ok {
+		c.transport = t.base
+	}
+
+	return c, nil
 }
 
 
@@ -216,38 +772,43 @@ func NewClient(baseURL string, cookiesPath string, encryptCookies bool) (*Client
 
 
 func (c *Client) LoadCookies(cookieFile string) error {
+	cookies, err := c.readCookies(cookieFile)
+	if err != nil {
+		return err
+	}
+	parsedURL, err := url.Parse(c.baseURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse base URL: %w", err)
+	}
+	c.client.Jar.SetCookies(parsedURL, cookies)
+	return nil
+}
+
+// readCookies reads the cookies saved in cookieFile, decrypting them if
+// needed.
+func (c *Client) readCookies(cookieFile string) ([]*http.Cookie, error) {
 	/* #nosec G304 */
 	data, err := os.ReadFile(cookieFile)
 	if err != nil {
-		return fmt.Errorf("failed to read cookie file: %v", err)
+		return nil, fmt.Errorf("failed to read cookie file: %w", err)
 	}
 
-	// Handle encrypted cookies
-	if c.encryptCookies {
-		password := c.cookiePassword
-		if password == "" {
-			password, err = crypto.PromptPassword("Enter password to decrypt cookies: ")
-			if err != nil {
-				return fmt.Errorf("failed to read password: %v", err)
-			}
-			if password == "" {
-				return fmt.Errorf("password cannot be empty")
-			}
+	// Encrypted files are recognised by their header whatever the flag says,
+	// and keep being saved encrypted. Files from before the header existed
+	// are only decrypted with the flag, unless they are plain cookies.
+	if encrypted, ok := bytes.CutPrefix(data, []byte(encryptedCookiesHeader)); ok {
+		c.encryptCookies = true
+		if data, err = c.decryptCookies(encrypted); err != nil {
+			return nil, err
 		}
-		data, err = crypto.DecryptData(data, password)
-		if err != nil {
-			return fmt.Errorf("failed to decrypt cookies: %v", err)
+	} else if c.encryptCookies && !isPlainCookies(data) {
+		if data, err = c.decryptCookies(data); err != nil {
+			return nil, err
 		}
-		c.cookiePassword = password // Store for later use
-	}
-
-	cookies := strings.Split(string(data), "\n")
-	parsedURL, err := url.Parse(c.baseURL)
-	if err != nil {
-		return fmt.Errorf("failed to parse base URL: %v", err)
 	}
 
-	for _, cookie := range cookies {
+	var cookies []*http.Cookie
+	for _, cookie := range strings.Split(string(data), "\n") {
 		if cookie == "" {
 			continue
 		}
@@ -255,21 +816,67 @@ func (c *Client) LoadCookies(cookieFile string) error {
 		if len(parts) != 2 {
 			continue
 		}
-		c.client.Jar.SetCookies(parsedURL, []*http.Cookie{
-			{
-				Name:  strings.TrimSpace(parts[0]),
-				Value: strings.TrimSpace(parts[1]),
-			},
+		cookies = append(cookies, &http.Cookie{
+			Name:  strings.TrimSpace(parts[0]),
+			Value: strings.TrimSpace(parts[1]),
 		})
 	}
+	return cookies, nil
+}
+
+// validateBaseURL rejects instance URLs whose host can't be right, so a typo
+// fails here rather than as a network error on the first request.
+func validateBaseURL(baseURL string) error {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid instance URL %q: %w", baseURL, err)
+	}
+	if u.Hostname() == "" {
+		return fmt.Errorf("invalid instance URL %q: no host name", baseURL)
+	}
+	// Internationalised names are checked in their punycode form
+	host, err := idna.ToASCII(u.Hostname())
+	if err != nil {
+		return fmt.Errorf("invalid instance URL %q: %w", baseURL, err)
+	}
+	for _, r := range host {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-._:", r)) {
+			return fmt.Errorf("invalid instance URL %q: %q isn't allowed in a host name", baseURL, r)
+		}
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("invalid instance URL %q: it shouldn't have a query or fragment", baseURL)
+	}
+	return nil
+}
 
+// Ping checks that the instance is reachable and is a Discourse forum. It
+// only fetches the small basic site info, so it is cheap to call before
+// logging in.
+func (c *Client) Ping() error {
+	resp, err := c.get(c.endpoint("/site/basic-info.json"))
+	if err != nil {
+		return fmt.Errorf("could not reach Discourse at %s: %w", c.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %s; is it a Discourse forum?", c.baseURL, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response body: %w", err)
+	}
+	if !gjson.ValidBytes(body) || !gjson.GetBytes(body, "title").Exists() {
+		return fmt.Errorf("%s doesn't look like a Discourse forum", c.baseURL)
+	}
 	return nil
 }
 
 func (c *Client) GetLatestTopics() (*Response, error) {
-	resp, err := c.client.Get(fmt.Sprintf("%s/latest.json", c.baseURL))
+	resp, err := c.get(c.endpoint("/latest.json"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch latest topics: %v", err)
+		return nil, fmt.Errorf("failed to fetch latest topics: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -280,7 +887,7 @@ func (c *Client) GetLatestTopics() (*Response, error) {
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	userCacheDir, err := os.UserCacheDir()
@@ -298,130 +905,82 @@ func (c *Client) GetLatestTopics() (*Response, error) {
 		}
 	}
 
-	if !gjson.ValidBytes(body) {
-		return nil, fmt.Errorf("invalid JSON response from server")
+	response, err := parseTopicList(body)
+	if err != nil {
+		return nil, err
 	}
+	c.EnrichTopicCategories(response.TopicList.Topics)
 
-	result := gjson.ParseBytes(body)
-	response := &Response{}
-
-	// Parse users
-	users := result.Get("users")
-	users.ForEach(func(_, value gjson.Result) bool {
-		results := gjson.GetMany(value.Raw, "id", "username", "name", "avatar_template", "trust_level", "moderator")
-		user := User{
-			ID:             int(results[0].Int()),
-			Username:       results[1].Str,
-			Name:           results[2].Str,
-			AvatarTemplate: results[3].Str,
-			TrustLevel:     int(results[4].Int()),
-			Moderator:      results[5].Bool(),
-		}
-		response.Users = append(response.Users, user)
-		return true
-	})
+	return response, nil
+}
 
-	// Parse topic list
-	topicList := result.Get("topic_list")
-	response.TopicList.CanCreateTopic = topicList.Get("can_create_topic").Bool()
-	response.TopicList.MoreTopicsURL = topicList.Get("more_topics_url").Str
-	response.TopicList.PerPage = int(topicList.Get("per_page").Int())
+func (c *Client) GetTopicPosts(topicID int) (*TopicResponse, error) {
+	return c.GetTopicPostsContext(context.Background(), topicID)
+}
 
-	// Parse topics
-	topics := topicList.Get("topics")
-	topics.ForEach(func(_, value gjson.Result) bool {
-		topic := Topic{
-			ID:                 int(value.Get("id").Int()),
-			Title:              value.Get("title").Str,
-			FancyTitle:         value.Get("fancy_title").Str,
-			Slug:               value.Get("slug").Str,
-			PostsCount:         int(value.Get("posts_count").Int()),
-			ReplyCount:         int(value.Get("reply_count").Int()),
-			HighestPostNumber:  int(value.Get("highest_post_number").Int()),
-			ImageURL:           value.Get("image_url").Str,
-			CreatedAt:          value.Get("created_at").Time(),
-			LastPostedAt:       value.Get("last_posted_at").Time(),
-			Bumped:             value.Get("bumped").Bool(),
-			BumpedAt:           value.Get("bumped_at").Time(),
-			Archetype:          value.Get("archetype").Str,
-			Unseen:             value.Get("unseen").Bool(),
-			LastReadPostNumber: int(value.Get("last_read_post_number").Int()),
-			Unread:             int(value.Get("unread").Int()),
-			NewPosts:           int(value.Get("new_posts").Int()),
-			UnreadPosts:        int(value.Get("unread_posts").Int()),
-			Pinned:             value.Get("pinned").Bool(),
-			Visible:            value.Get("visible").Bool(),
-			Closed:             value.Get("closed").Bool(),
-			Archived:           value.Get("archived").Bool(),
-			NotificationLevel:  int(value.Get("notification_level").Int()),
-			Bookmarked:         value.Get("bookmarked").Bool(),
-			Liked:              value.Get("liked").Bool(),
-			Views:              int(value.Get("views").Int()),
-			LikeCount:          int(value.Get("like_count").Int()),
-			LastPosterUsername: value.Get("last_poster_username").Str,
-			CategoryID:         int(value.Get("category_id").Int()),
-		}
+// GetTopicPostsContext is GetTopicPosts, but cancelling ctx aborts the fetch
+// between batches, including during the cooldown wait.
+func (c *Client) GetTopicPostsContext(ctx context.Context, topicID int) (*TopicResponse, error) {
+	_, response, err := c.GetTopicContext(ctx, topicID)
+	return response, err
+}
 
-		// Parse tags
-		tags := value.Get("tags")
-		tags.ForEach(func(_, tag gjson.Result) bool {
-			topic.Tags = append(topic.Tags, tag.Str)
-			return true
-		})
+// GetTopic returns a topic's own metadata, such as its category, tags,
+// creator and participant count, along with all of its posts, from the
+// same requests GetTopicPosts makes.
+func (c *Client) GetTopic(topicID int) (*Topic, *TopicResponse, error) {
+	return c.GetTopicContext(context.Background(), topicID)
+}
 
-		response.TopicList.Topics = append(response.TopicList.Topics, topic)
-		return true
-	})
+// GetTopicContext is GetTopic, but cancelling ctx aborts the fetch between
+// batches, including during the cooldown wait.
+func (c *Client) GetTopicContext(ctx context.Context, topicID int) (*Topic, *TopicResponse, error) {
+	return c.GetTopicChunkedContext(ctx, topicID, 0, nil)
+}
 
-	categories, err := c.GetCategories()
-	if err != nil {
-		log.Printf("Warning: failed to fetch categories: %v", err)
-	} else {
-		categoryMap := make(map[int]struct {
-			Name  string
-			Color string
-		})
-		for _, category := range categories.CategoryList.Categories {
-			categoryMap[category.ID] = struct {
-				Name  string
-				Color string
-			}{
-				Name:  category.Name,
-				Color: category.Color,
-			}
-		}
+// GetTopicPostsChunked fetches a topic's posts chunkSize at a time, calling
+// cb with each chunk in stream order as it arrives, so long topics can be
+// shown before they finish loading. A chunkSize of 0 uses the post batch
+// size.
+func (c *Client) GetTopicPostsChunked(topicID int, chunkSize int, cb func([]Post)) error {
+	_, _, err := c.GetTopicChunkedContext(context.Background(), topicID, chunkSize, cb)
+	return err
+}
 
-		for i := range response.TopicList.Topics {
-			if cat, ok := categoryMap[response.TopicList.Topics[i].CategoryID]; ok {
-				response.TopicList.Topics[i].CategoryName = cat.Name
-				response.TopicList.Topics[i].CategoryColor = cat.Color
-			}
-		}
+// GetTopicChunkedContext is GetTopicContext, but calls cb, when not nil,
+// with the posts that come with the topic and then with every chunk of
+// chunkSize posts fetched after them.
+func (c *Client) GetTopicChunkedContext(ctx context.Context, topicID, chunkSize int, cb func([]Post)) (*Topic, *TopicResponse, error) {
+	if chunkSize <= 0 {
+		chunkSize = c.postBatchSize
 	}
+	chunkSize = min(chunkSize, MaxPostBatchSize)
 
-	return response, nil
-}
-
-func (c *Client) GetTopicPosts(topicID int) (*TopicResponse, error) {
 	// Fetch initial data to collect all post IDs
-	resp, err := c.client.Get(fmt.Sprintf("%s/t/%d.json", c.baseURL, topicID))
+	resp, err := c.get(c.endpoint(fmt.Sprintf("/t/%d.json", topicID)))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch initial topic data: %w", err)
+		return nil, nil, fmt.Errorf("failed to fetch initial topic data: %w", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error fetching initial topic data: %s - %s", resp.Status, string(body))
+		return nil, nil, fmt.Errorf("API error fetching initial topic data: %s - %s", resp.Status, string(body))
 	}
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read initial topic response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read initial topic response body: %w", err)
 	}
 	if !gjson.ValidBytes(data) {
-		return nil, fmt.Errorf("invalid JSON response from server")
+		return nil, nil, fmt.Errorf("invalid JSON response from server")
 	}
 	initial := gjson.ParseBytes(data)
 
+	topics := []Topic{parseTopic(initial)}
+	topics[0].ParticipantCount = int(initial.Get("participant_count").Int())
+	topics[0].CreatorUsername = initial.Get("details.created_by.username").Str
+	c.EnrichTopicCategories(topics)
+	topic := &topics[0]
+
 	// Collect post IDs
 	idsResult := initial.Get("post_stream.stream")
 	var postIDs []int
@@ -435,47 +994,180 @@ func (c *Client) GetTopicPosts(topicID int) (*TopicResponse, error) {
 		response := &TopicResponse{}
 		posts := initial.Get("post_stream.posts")
 		posts.ForEach(func(_, value gjson.Result) bool {
-			results := gjson.GetMany(value.Raw, "id", "name", "username", "created_at", "cooked", "post_number", "reply_count", "topic_id", "topic_slug", "reads", "score")
-			post := Post{
-				ID:         int(results[0].Int()),
-				Name:       results[1].Str,
-				Username:   results[2].Str,
-				CreatedAt:  results[3].Time(),
-				Cooked:     results[4].Str,
-				PostNumber: int(results[5].Int()),
-				ReplyCount: int(results[6].Int()),
-				TopicID:    int(results[7].Int()),
-				TopicSlug:  results[8].Str,
-				Reads:      int(results[9].Int()),
-				Score:      results[10].Float(),
-			}
-			actions := value.Get("actions_summary")
-			actions.ForEach(func(_, a gjson.Result) bool {
-				actionResults := gjson.GetMany(a.Raw, "id", "count", "acted", "can_undo")
-				action := ActionsSummary{
-					ID:      int(actionResults[0].Int()),
-					Count:   int(actionResults[1].Int()),
-					Acted:   actionResults[2].Bool(),
-					CanUndo: actionResults[3].Bool(),
-				}
-				post.ActionsSummary = append(post.ActionsSummary, action)
-				return true
-			})
-			response.PostStream.Posts = append(response.PostStream.Posts, post)
+			response.PostStream.Posts = append(response.PostStream.Posts, parsePost(value))
 			return true
 		})
-		return response, nil
+		if cb != nil {
+			cb(response.PostStream.Posts)
+		}
+		c.cacheTopicPosts(topicID, response)
+		return topic, response, nil
 	}
 
-	// Throttle before fetching all posts
-	time.Sleep(c.pageCooldown)
-
-	// Fetch all posts by ID
-	allURL := fmt.Sprintf("%s/t/%d/posts.json", c.baseURL, topicID)
-	req, err := http.NewRequest("GET", allURL, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create full posts request: %w", err)
-	}
+	// The first posts come inline; only fetch the rest of the stream
+	var inline []Post
+	have := make(map[int]bool)
+	initial.Get("post_stream.posts").ForEach(func(_, value gjson.Result) bool {
+		post := parsePost(value)
+		inline = append(inline, post)
+		have[post.ID] = true
+		return true
+	})
+	var missing []int
+	for _, id := range postIDs {
+		if !have[id] {
+			missing = append(missing, id)
+		}
+	}
+	var onBatch func([]int, []Post)
+	if cb != nil {
+		cb(mergePostsByStream(postIDs, inline))
+		onBatch = func(ids []int, batch []Post) {
+			cb(mergePostsByStream(ids, batch))
+		}
+	}
+	fetched, err := c.fetchPostsInBatches(ctx, topicID, missing, chunkSize, onBatch)
+	if err != nil {
+		return nil, nil, err
+	}
+	posts := mergePostsByStream(postIDs, inline, fetched)
+	// Older versions of the solved plugin only report the answer on the topic
+	if accepted := initial.Get("accepted_answer.post_number"); accepted.Exists() {
+		for i := range posts {
+			if posts[i].PostNumber == int(accepted.Int()) {
+				posts[i].AcceptedAnswer = true
+			}
+		}
+	}
+	response := &TopicResponse{}
+	response.PostStream.Posts = posts
+	response.PostStream.Stream = postIDs
+	c.cacheTopicPosts(topicID, response)
+	return topic, response, nil
+}
+
+// mergePostsByStream orders posts from several sources by the topic's post
+// stream, keeping one copy of each post. Posts the stream doesn't list are
+// dropped and stream IDs no source has (deleted posts) are skipped.
+func mergePostsByStream(stream []int, sources ...[]Post) []Post {
+	byID := make(map[int]Post)
+	for _, source := range sources {
+		for _, post := range source {
+			if _, ok := byID[post.ID]; !ok {
+				byID[post.ID] = post
+			}
+		}
+	}
+	merged := make([]Post, 0, len(stream))
+	for _, id := range stream {
+		if post, ok := byID[id]; ok {
+			merged = append(merged, post)
+			delete(byID, id)
+		}
+	}
+	return merged
+}
+
+// fetchPostsInBatches fetches posts by ID, batchSize at a time, handing each
+// batch to onBatch, when not nil, as it arrives.
+func (c *Client) fetchPostsInBatches(ctx context.Context, topicID int, postIDs []int, batchSize int, onBatch func([]int, []Post)) ([]Post, error) {
+	var all []Post
+	for start := 0; start < len(postIDs); start += batchSize {
+		end := min(start+batchSize, len(postIDs))
+		// Throttle before each batch
+		if err := c.waitCooldown(ctx); err != nil {
+			return nil, err
+		}
+
+		posts, err := c.fetchPostsByID(topicID, postIDs[start:end])
+		if err != nil {
+			return nil, err
+		}
+		if onBatch != nil {
+			onBatch(postIDs[start:end], posts)
+		}
+		all = append(all, posts...)
+	}
+	return all, nil
+}
+
+// GetTopicPostsAround fetches a window of posts centred on postNumber: up to
+// before posts ahead of it and after posts following it. Like Discourse's
+// web client it only needs the topic's post ID stream, so jumping deep into a
+// huge topic doesn't download everything before the target.
+func (c *Client) GetTopicPostsAround(topicID, postNumber, before, after int) (*TopicResponse, error) {
+	if before < 0 || after < 0 {
+		return nil, fmt.Errorf("window sizes must not be negative")
+	}
+	resp, err := c.get(c.endpoint(fmt.Sprintf("/t/%d/%d.json", topicID, postNumber)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch topic at post %d: %w", postNumber, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error fetching topic at post %d: %s - %s", postNumber, resp.Status, string(body))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read topic response body: %w", err)
+	}
+	if !gjson.ValidBytes(data) {
+		return nil, fmt.Errorf("invalid JSON response from server")
+	}
+	result := gjson.ParseBytes(data)
+
+	var stream []int
+	result.Get("post_stream.stream").ForEach(func(_, id gjson.Result) bool {
+		stream = append(stream, int(id.Int()))
+		return true
+	})
+
+	// The response includes the posts near postNumber; use the closest one at
+	// or after it (the target may have been deleted) to find our place.
+	targetID := 0
+	closest := -1
+	result.Get("post_stream.posts").ForEach(func(_, value gjson.Result) bool {
+		number := int(value.Get("post_number").Int())
+		if number >= postNumber && (closest == -1 || number < closest) {
+			closest = number
+			targetID = int(value.Get("id").Int())
+		}
+		return true
+	})
+
+	index := -1
+	for i, id := range stream {
+		if id == targetID {
+			index = i
+			break
+		}
+	}
+	if index == -1 {
+		if len(stream) == 0 {
+			return nil, fmt.Errorf("topic %d has no posts", topicID)
+		}
+		index = len(stream) - 1
+	}
+
+	start := max(index-before, 0)
+	end := min(index+after+1, len(stream))
+	posts, err := c.fetchPostsInBatches(context.Background(), topicID, stream[start:end], c.postBatchSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	response := &TopicResponse{}
+	response.PostStream.Posts = posts
+	response.PostStream.Stream = stream
+	return response, nil
+}
+
+func (c *Client) fetchPostsByID(topicID int, postIDs []int) ([]Post, error) {
+	allURL := c.endpoint(fmt.Sprintf("/t/%d/posts.json", topicID))
+	req, err := http.NewRequest("GET", allURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create full posts request: %w", err)
+	}
 	q := req.URL.Query()
 	for _, id := range postIDs {
 		q.Add("post_ids[]", fmt.Sprintf("%d", id))
@@ -483,7 +1175,7 @@ func (c *Client) GetTopicPosts(topicID int) (*TopicResponse, error) {
 	q.Add("include_suggested", "false")
 	req.URL.RawQuery = q.Encode()
 
-	fullResp, err := c.client.Do(req)
+	fullResp, err := c.doRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch full posts: %w", err)
 	}
@@ -499,40 +1191,12 @@ func (c *Client) GetTopicPosts(topicID int) (*TopicResponse, error) {
 	if !gjson.ValidBytes(fullData) {
 		return nil, fmt.Errorf("invalid JSON response from server")
 	}
-	result := gjson.ParseBytes(fullData)
-	response := &TopicResponse{}
-	postsArray := result.Get("post_stream.posts")
-	postsArray.ForEach(func(_, value gjson.Result) bool {
-		results := gjson.GetMany(value.Raw, "id", "name", "username", "created_at", "cooked", "post_number", "reply_count", "topic_id", "topic_slug", "reads", "score")
-		post := Post{
-			ID:         int(results[0].Int()),
-			Name:       results[1].Str,
-			Username:   results[2].Str,
-			CreatedAt:  results[3].Time(),
-			Cooked:     results[4].Str,
-			PostNumber: int(results[5].Int()),
-			ReplyCount: int(results[6].Int()),
-			TopicID:    int(results[7].Int()),
-			TopicSlug:  results[8].Str,
-			Reads:      int(results[9].Int()),
-			Score:      results[10].Float(),
-		}
-		actions := value.Get("actions_summary")
-		actions.ForEach(func(_, a gjson.Result) bool {
-			actionResults := gjson.GetMany(a.Raw, "id", "count", "acted", "can_undo")
-			action := ActionsSummary{
-				ID:      int(actionResults[0].Int()),
-				Count:   int(actionResults[1].Int()),
-				Acted:   actionResults[2].Bool(),
-				CanUndo: actionResults[3].Bool(),
-			}
-			post.ActionsSummary = append(post.ActionsSummary, action)
-			return true
-		})
-		response.PostStream.Posts = append(response.PostStream.Posts, post)
+	var posts []Post
+	gjson.GetBytes(fullData, "post_stream.posts").ForEach(func(_, value gjson.Result) bool {
+		posts = append(posts, parsePost(value))
 		return true
 	})
-	return response, nil
+	return posts, nil
 }
 
 func (c *Client) GetTopicPostsPage(topicID, page int) (*TopicResponse, error) {
@@ -540,7 +1204,7 @@ func (c *Client) GetTopicPostsPage(topicID, page int) (*TopicResponse, error) {
 		// Only initial page supported; fall back to full fetch
 		return c.GetTopicPosts(topicID)
 	}
-	resp, err := c.client.Get(fmt.Sprintf("%s/t/%d.json", c.baseURL, topicID))
+	resp, err := c.get(c.endpoint(fmt.Sprintf("/t/%d.json", topicID)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch initial topic page: %w", err)
 	}
@@ -560,42 +1224,16 @@ func (c *Client) GetTopicPostsPage(topicID, page int) (*TopicResponse, error) {
 	response := &TopicResponse{}
 	posts := result.Get("post_stream.posts")
 	posts.ForEach(func(_, value gjson.Result) bool {
-		results := gjson.GetMany(value.Raw, "id", "name", "username", "created_at", "cooked", "post_number", "reply_count", "topic_id", "topic_slug", "reads", "score")
-		post := Post{
-			ID:         int(results[0].Int()),
-			Name:       results[1].Str,
-			Username:   results[2].Str,
-			CreatedAt:  results[3].Time(),
-			Cooked:     results[4].Str,
-			PostNumber: int(results[5].Int()),
-			ReplyCount: int(results[6].Int()),
-			TopicID:    int(results[7].Int()),
-			TopicSlug:  results[8].Str,
-			Reads:      int(results[9].Int()),
-			Score:      results[10].Float(),
-		}
-		actions := value.Get("actions_summary")
-		actions.ForEach(func(_, a gjson.Result) bool {
-			actionResults := gjson.GetMany(a.Raw, "id", "count", "acted", "can_undo")
-			action := ActionsSummary{
-				ID:      int(actionResults[0].Int()),
-				Count:   int(actionResults[1].Int()),
-				Acted:   actionResults[2].Bool(),
-				CanUndo: actionResults[3].Bool(),
-			}
-			post.ActionsSummary = append(post.ActionsSummary, action)
-			return true
-		})
-		response.PostStream.Posts = append(response.PostStream.Posts, post)
+		response.PostStream.Posts = append(response.PostStream.Posts, parsePost(value))
 		return true
 	})
 	return response, nil
 }
 
 func (c *Client) GetCSRFToken() (string, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/session/csrf", c.baseURL), nil)
+	req, err := http.NewRequest("GET", c.endpoint("/session/csrf"), nil)
 	if err != nil {
-		return "", fmt.Errorf("failed to create CSRF request: %v", err)
+		return "", fmt.Errorf("failed to create CSRF request: %w", err)
 	}
 
 	req.Header.Set("accept", "application/json, text/javascript, */*; q=0.01")
@@ -603,9 +1241,9 @@ func (c *Client) GetCSRFToken() (string, error) {
 	req.Header.Set("x-requested-with", "XMLHttpRequest")
 	req.Header.Set("user-agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36")
 
-	resp, err := c.client.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch CSRF token: %v", err)
+		return "", fmt.Errorf("failed to fetch CSRF token: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -616,7 +1254,7 @@ func (c *Client) GetCSRFToken() (string, error) {
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
+		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if !gjson.ValidBytes(body) {
@@ -637,47 +1275,81 @@ func (c *Client) GetCSRFToken() (string, error) {
 }
 
 func (c *Client) Login(username, password string) error {
+	return c.LoginWithSecondFactor(username, password, "")
+}
+
+// secondFactorTOTP is Discourse's second_factor_method for authenticator
+// app codes.
+const secondFactorTOTP = 1
+
+// LoginWithSecondFactor is Login with the current code of the account's
+// authenticator app, for accounts with two-factor authentication. An empty
+// token sends none.
+func (c *Client) LoginWithSecondFactor(username, password, token string) error {
 	csrfToken, err := c.GetCSRFToken()
 	if err != nil {
-		return fmt.Errorf("failed to get CSRF token: %v", err)
+		return fmt.Errorf("failed to get CSRF token: %w", err)
 	}
 
 	data := url.Values{}
 	data.Set("login", username)
 	data.Set("password", password)
 	data.Set("authenticity_token", csrfToken)
+	if token != "" {
+		data.Set("second_factor_token", strings.TrimSpace(token))
+		data.Set("second_factor_method", strconv.Itoa(secondFactorTOTP))
+	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/session", c.baseURL), strings.NewReader(data.Encode()))
+	req, err := http.NewRequest("POST", c.endpoint("/session"), strings.NewReader(data.Encode()))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %v", err)
+		return fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("X-CSRF-Token", csrfToken)
 	req.Header.Set("X-Requested-With", "XMLHttpRequest")
 
-	resp, err := c.client.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
-		return fmt.Errorf("failed to login: %v", err)
+		return fmt.Errorf("failed to login: %w", err)
 	}
 	defer resp.Body.Close()
 
+	body, _ := io.ReadAll(resp.Body)
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
 		return fmt.Errorf("login failed: %s - %s", resp.Status, string(body))
 	}
+	// Refused logins, wrong codes included, still answer 200 with an error
+	if result := gjson.ParseBytes(body); result.Get("error").Exists() {
+		secondFactor := result.Get("reason").Str == "invalid_second_factor" || result.Get("second_factor_required").Bool()
+		if secondFactor && token == "" {
+			return ErrSecondFactorRequired
+		}
+		return errors.New(result.Get("error").Str)
+	}
 
-	if err := c.SaveCookies(c.cookiesPath); err != nil {
-		return fmt.Errorf("failed to save cookies after login: %v", err)
+	if err := c.SaveCookies(c.CookiesPath()); err != nil {
+		return fmt.Errorf("failed to save cookies after login: %w", err)
 	}
 
 	return nil
 }
 
+// SetCookies adds cookies obtained elsewhere (e.g. from a browser) to the
+// client's session for the instance.
+func (c *Client) SetCookies(cookies []*http.Cookie) error {
+	parsedURL, err := url.Parse(c.baseURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse base URL: %w", err)
+	}
+	c.client.Jar.SetCookies(parsedURL, cookies)
+	return nil
+}
+
 func (c *Client) SaveCookies(cookieFile string) error {
 	parsedURL, err := url.Parse(c.baseURL)
 	if err != nil {
-		return fmt.Errorf("failed to parse base URL: %v", err)
+		return fmt.Errorf("failed to parse base URL: %w", err)
 	}
 
 	cookies := c.client.Jar.Cookies(parsedURL)
@@ -686,7 +1358,7 @@ func (c *Client) SaveCookies(cookieFile string) error {
 	}
 
 	var cookieStrings []string
-	for _, cookie := range cookies {
+	for _, cookie := range dedupeCookies(cookies) {
 		cookieStrings = append(cookieStrings, fmt.Sprintf("%s=%s", cookie.Name, cookie.Value))
 	}
 
@@ -698,7 +1370,7 @@ func (c *Client) SaveCookies(cookieFile string) error {
 		if password == "" {
 			password, err = crypto.PromptPassword("Enter password to encrypt cookies: ")
 			if err != nil {
-				return fmt.Errorf("failed to read password: %v", err)
+				return fmt.Errorf("failed to read password: %w", err)
 			}
 			if password == "" {
 				return fmt.Errorf("password cannot be empty")
@@ -706,18 +1378,80 @@ func (c *Client) SaveCookies(cookieFile string) error {
 		}
 		data, err = crypto.EncryptData(data, password)
 		if err != nil {
-			return fmt.Errorf("failed to encrypt cookies: %v", err)
+			return fmt.Errorf("failed to encrypt cookies: %w", err)
 		}
 		c.cookiePassword = password // Store for later use
+		data = append([]byte(encryptedCookiesHeader), data...)
 	}
 
 	return os.WriteFile(cookieFile, data, 0600) //nosec G306
 }
 
+// encryptedCookiesHeader starts cookie files saved with encryption.
+const encryptedCookiesHeader = "discourse-tui encrypted cookies v1\n"
+
+func (c *Client) decryptCookies(data []byte) ([]byte, error) {
+	password := c.cookiePassword
+	if password == "" {
+		var err error
+		password, err = crypto.PromptPassword("Enter password to decrypt cookies: ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read password: %w", err)
+		}
+		if password == "" {
+			return nil, fmt.Errorf("password cannot be empty")
+		}
+	}
+	data, err := crypto.DecryptData(data, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt cookies: %w", err)
+	}
+	c.cookiePassword = password // Store for later use
+	return data, nil
+}
+
+// isPlainCookies reports whether data is a plaintext cookie file, so that
+// turning encryption on doesn't lock out an existing login.
+func isPlainCookies(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" && !strings.Contains(line, "=") {
+			return false
+		}
+	}
+	return true
+}
+
+// sessionCookies are the cookies Discourse needs to recognise a login.
+var sessionCookies = map[string]bool{"_t": true, "_forum_session": true}
+
+// dedupeCookies keeps one cookie per name. The jar returns cookies set on
+// different paths under the same name, most specific path first, and saving
+// them all makes LoadCookies apply whichever comes last. An empty session
+// cookie (one the server cleared on a narrower path) never replaces a real one.
+func dedupeCookies(cookies []*http.Cookie) []*http.Cookie {
+	var deduped []*http.Cookie
+	index := make(map[string]int)
+	for _, cookie := range cookies {
+		i, seen := index[cookie.Name]
+		if !seen {
+			index[cookie.Name] = len(deduped)
+			deduped = append(deduped, cookie)
+			continue
+		}
+		if sessionCookies[cookie.Name] && deduped[i].Value == "" && cookie.Value != "" {
+			deduped[i] = cookie
+		}
+	}
+	return deduped
+}
+
 func (c *Client) RefreshTopics() (*Response, error) {
-	resp, err := c.client.Get(fmt.Sprintf("%s/latest.json", c.baseURL))
+	resp, err := c.get(c.endpoint("/latest.json"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch latest topics: %v", err)
+		return nil, fmt.Errorf("failed to fetch latest topics: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -728,7 +1462,7 @@ func (c *Client) RefreshTopics() (*Response, error) {
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	userCacheDir, err := os.UserCacheDir()
@@ -746,81 +1480,13 @@ func (c *Client) RefreshTopics() (*Response, error) {
 		}
 	}
 
-	result := gjson.ParseBytes(body)
-	response := &Response{}
-
-	users := result.Get("users")
-	users.ForEach(func(_, value gjson.Result) bool {
-		user := User{
-			ID:             int(value.Get("id").Int()),
-			Username:       value.Get("username").Str,
-			Name:           value.Get("name").Str,
-			AvatarTemplate: value.Get("avatar_template").Str,
-			TrustLevel:     int(value.Get("trust_level").Int()),
-			Moderator:      value.Get("moderator").Bool(),
-		}
-		response.Users = append(response.Users, user)
-		return true
-	})
-
-	// Parse topic list
-	topicList := result.Get("topic_list")
-	response.TopicList.CanCreateTopic = topicList.Get("can_create_topic").Bool()
-	response.TopicList.MoreTopicsURL = topicList.Get("more_topics_url").Str
-	response.TopicList.PerPage = int(topicList.Get("per_page").Int())
-
-	// Parse topics
-	topics := topicList.Get("topics")
-	topics.ForEach(func(_, value gjson.Result) bool {
-		topic := Topic{
-			ID:                 int(value.Get("id").Int()),
-			Title:              value.Get("title").Str,
-			FancyTitle:         value.Get("fancy_title").Str,
-			Slug:               value.Get("slug").Str,
-			PostsCount:         int(value.Get("posts_count").Int()),
-			ReplyCount:         int(value.Get("reply_count").Int()),
-			HighestPostNumber:  int(value.Get("highest_post_number").Int()),
-			ImageURL:           value.Get("image_url").Str,
-			CreatedAt:          value.Get("created_at").Time(),
-			LastPostedAt:       value.Get("last_posted_at").Time(),
-			Bumped:             value.Get("bumped").Bool(),
-			BumpedAt:           value.Get("bumped_at").Time(),
-			Archetype:          value.Get("archetype").Str,
-			Unseen:             value.Get("unseen").Bool(),
-			LastReadPostNumber: int(value.Get("last_read_post_number").Int()),
-			Unread:             int(value.Get("unread").Int()),
-			NewPosts:           int(value.Get("new_posts").Int()),
-			UnreadPosts:        int(value.Get("unread_posts").Int()),
-			Pinned:             value.Get("pinned").Bool(),
-			Visible:            value.Get("visible").Bool(),
-			Closed:             value.Get("closed").Bool(),
-			Archived:           value.Get("archived").Bool(),
-			NotificationLevel:  int(value.Get("notification_level").Int()),
-			Bookmarked:         value.Get("bookmarked").Bool(),
-			Liked:              value.Get("liked").Bool(),
-			Views:              int(value.Get("views").Int()),
-			LikeCount:          int(value.Get("like_count").Int()),
-			LastPosterUsername: value.Get("last_poster_username").Str,
-			CategoryID:         int(value.Get("category_id").Int()),
-		}
-
-		tags := value.Get("tags")
-		tags.ForEach(func(_, tag gjson.Result) bool {
-			topic.Tags = append(topic.Tags, tag.Str)
-			return true
-		})
-
-		response.TopicList.Topics = append(response.TopicList.Topics, topic)
-		return true
-	})
-
-	return response, nil
+	return parseTopicList(body)
 }
 
 func (c *Client) GetCategories() (*CategoryResponse, error) {
 	userCacheDir, err := os.UserCacheDir()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get cache directory: %v", err)
+		return nil, fmt.Errorf("failed to get cache directory: %w", err)
 	}
 
 	instanceDir := filepath.Join(userCacheDir, "discourse-tui-client", "instances", strings.TrimPrefix(strings.TrimPrefix(c.baseURL, "https://"), "http://"))
@@ -833,18 +1499,7 @@ func (c *Client) GetCategories() (*CategoryResponse, error) {
 
 		categories := result.Get("category_list.categories")
 		categories.ForEach(func(_, value gjson.Result) bool {
-			category := Category{
-				ID:          int(value.Get("id").Int()),
-				Name:        value.Get("name").Str,
-				Color:       value.Get("color").Str,
-				TextColor:   value.Get("text_color").Str,
-				Slug:        value.Get("slug").Str,
-				TopicCount:  int(value.Get("topic_count").Int()),
-				PostCount:   int(value.Get("post_count").Int()),
-				Position:    int(value.Get("position").Int()),
-				Description: value.Get("description").Str,
-			}
-			response.CategoryList.Categories = append(response.CategoryList.Categories, category)
+			response.CategoryList.Categories = append(response.CategoryList.Categories, parseCategory(value))
 			return true
 		})
 
@@ -854,9 +1509,9 @@ func (c *Client) GetCategories() (*CategoryResponse, error) {
 		return response, nil
 	}
 
-	resp, err := c.client.Get(fmt.Sprintf("%s/categories.json", c.baseURL))
+	resp, err := c.get(c.endpoint("/categories.json"))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch categories: %v", err)
+		return nil, fmt.Errorf("failed to fetch categories: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -867,7 +1522,7 @@ func (c *Client) GetCategories() (*CategoryResponse, error) {
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if err := os.MkdirAll(instanceDir, 0750); err != nil {
@@ -883,18 +1538,7 @@ func (c *Client) GetCategories() (*CategoryResponse, error) {
 
 	categories := result.Get("category_list.categories")
 	categories.ForEach(func(_, value gjson.Result) bool {
-		category := Category{
-			ID:          int(value.Get("id").Int()),
-			Name:        value.Get("name").Str,
-			Color:       value.Get("color").Str,
-			TextColor:   value.Get("text_color").Str,
-			Slug:        value.Get("slug").Str,
-			TopicCount:  int(value.Get("topic_count").Int()),
-			PostCount:   int(value.Get("post_count").Int()),
-			Position:    int(value.Get("position").Int()),
-			Description: value.Get("description").Str,
-		}
-		response.CategoryList.Categories = append(response.CategoryList.Categories, category)
+		response.CategoryList.Categories = append(response.CategoryList.Categories, parseCategory(value))
 		return true
 	})
 
@@ -904,6 +1548,229 @@ func (c *Client) GetCategories() (*CategoryResponse, error) {
 	return response, nil
 }
 
+func parsePost(value gjson.Result) Post {
+	results := gjson.GetMany(value.Raw, "id", "name", "username", "created_at", "cooked", "post_number", "reply_count", "topic_id", "topic_slug", "reads", "score", "version", "can_view_edit_history", "post_type", "action_code", "accepted_answer", "reply_to_post_number")
+	post := Post{
+		ID:                 int(results[0].Int()),
+		Name:               results[1].Str,
+		Username:           results[2].Str,
+		CreatedAt:          results[3].Time(),
+		Cooked:             results[4].Str,
+		PostNumber:         int(results[5].Int()),
+		ReplyCount:         int(results[6].Int()),
+		TopicID:            int(results[7].Int()),
+		TopicSlug:          results[8].Str,
+		Reads:              int(results[9].Int()),
+		Score:              results[10].Float(),
+		Version:            int(results[11].Int()),
+		CanViewEditHistory: results[12].Bool(),
+		PostType:           int(results[13].Int()),
+		ActionCode:         results[14].Str,
+		AcceptedAnswer:     results[15].Bool(),
+		ReplyToPostNumber:  int(results[16].Int()),
+	}
+	actions := value.Get("actions_summary")
+	actions.ForEach(func(_, a gjson.Result) bool {
+		actionResults := gjson.GetMany(a.Raw, "id", "count", "acted", "can_undo")
+		action := ActionsSummary{
+			ID:      int(actionResults[0].Int()),
+			Count:   int(actionResults[1].Int()),
+			Acted:   actionResults[2].Bool(),
+			CanUndo: actionResults[3].Bool(),
+		}
+		post.ActionsSummary = append(post.ActionsSummary, action)
+		return true
+	})
+	return post
+}
+
+func parseCategory(value gjson.Result) Category {
+	category := Category{
+		ID:                  int(value.Get("id").Int()),
+		Name:                value.Get("name").Str,
+		Color:               value.Get("color").Str,
+		TextColor:           value.Get("text_color").Str,
+		Slug:                value.Get("slug").Str,
+		TopicCount:          int(value.Get("topic_count").Int()),
+		PostCount:           int(value.Get("post_count").Int()),
+		Position:            int(value.Get("position").Int()),
+		Description:         value.Get("description").Str,
+		MinimumRequiredTags: int(value.Get("minimum_required_tags").Int()),
+		Permission:          int(value.Get("permission").Int()),
+	}
+	value.Get("subcategory_ids").ForEach(func(_, id gjson.Result) bool {
+		category.SubcategoryIDs = append(category.SubcategoryIDs, int(id.Int()))
+		return true
+	})
+	value.Get("required_tag_groups").ForEach(func(_, group gjson.Result) bool {
+		category.RequiredTagGroups = append(category.RequiredTagGroups, RequiredTagGroup{
+			Name:     group.Get("name").Str,
+			MinCount: int(group.Get("min_count").Int()),
+		})
+		return true
+	})
+	return category
+}
+
+// ValidateNewTopic checks a new topic's category and tags against the cached
+// category list, catching the common rejections before CreateTopic is called.
+// If the categories can't be loaded the check is skipped and the server decides.
+func (c *Client) ValidateNewTopic(categoryID int, tags []string) error {
+	categories, err := c.GetCategories()
+	if err != nil {
+		log.Printf("Skipping new topic validation: %v", err)
+		return nil
+	}
+
+	category, err := CheckNewTopicCategory(categories.CategoryList.Categories, categoryID)
+	if err != nil || category == nil {
+		return err
+	}
+
+	required := category.MinimumRequiredTags
+	for _, group := range category.RequiredTagGroups {
+		required += group.MinCount
+	}
+	if required > 0 && len(tags) < required {
+		if required == 1 {
+			return fmt.Errorf("this category requires at least one tag")
+		}
+		return fmt.Errorf("this category requires at least %d tags", required)
+	}
+	return nil
+}
+
+// CheckNewTopicCategory reports whether a topic may be created in categoryID.
+// The category is returned when its rules are known; categories.json only
+// lists the IDs of subcategories, so for those it is nil.
+func CheckNewTopicCategory(categories []Category, categoryID int) (*Category, error) {
+	for i, cat := range categories {
+		if cat.ID == categoryID {
+			if cat.Permission != 0 && cat.Permission != CategoryPermissionFull {
+				return nil, fmt.Errorf("you can't create topics in %s", cat.Name)
+			}
+			return &categories[i], nil
+		}
+		for _, id := range cat.SubcategoryIDs {
+			if id == categoryID {
+				return nil, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("category %d doesn't exist", categoryID)
+}
+
+// GetPostRevision fetches one revision of a post; rev is a revision number
+// or "latest".
+func (c *Client) GetPostRevision(postID int, rev string) (*Revision, error) {
+	resp, err := c.get(c.endpoint(fmt.Sprintf("/posts/%d/revisions/%s.json", postID, url.PathEscape(rev))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch revision: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("post %d has no revisions", postID)
+	}
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, fmt.Errorf("you don't have permission to view this post's edit history")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("invalid JSON response from server")
+	}
+
+	result := gjson.ParseBytes(body)
+	revision := &Revision{
+		PostID:           int(result.Get("post_id").Int()),
+		CurrentRevision:  int(result.Get("current_revision").Int()),
+		PreviousRevision: int(result.Get("previous_revision").Int()),
+		Username:         result.Get("username").Str,
+		CreatedAt:        result.Get("created_at").Time(),
+	}
+	revision.Before, revision.After = parseSideBySide(result.Get("body_changes.side_by_side_markdown").Str)
+	return revision, nil
+}
+
+var sideBySideRow = regexp.MustCompile(`(?s)<tr>\s*<td[^>]*>(.*?)</td>\s*<td[^>]*>(.*?)</td>\s*</tr>`)
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// parseSideBySide recovers the raw text before and after an edit from the
+// two-column markdown diff table Discourse renders for revisions.
+func parseSideBySide(table string) (before, after string) {
+	var beforeLines, afterLines []string
+	for _, row := range sideBySideRow.FindAllStringSubmatch(table, -1) {
+		left := html.UnescapeString(htmlTag.ReplaceAllString(row[1], ""))
+		right := html.UnescapeString(htmlTag.ReplaceAllString(row[2], ""))
+		inserted := strings.Contains(row[0], "diff-ins")
+		deleted := strings.Contains(row[0], "diff-del")
+		// A line that was only added (or only removed) has an empty cell on the other side.
+		if left != "" || !inserted || deleted {
+			beforeLines = append(beforeLines, left)
+		}
+		if right != "" || !deleted || inserted {
+			afterLines = append(afterLines, right)
+		}
+	}
+	return strings.Join(beforeLines, "\n"), strings.Join(afterLines, "\n")
+}
+
+// GetPostJSON returns a post exactly as the API serves it, pretty-printed.
+func (c *Client) GetPostJSON(postID int) ([]byte, error) {
+	resp, err := c.get(c.endpoint(fmt.Sprintf("/posts/%d.json", postID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch post: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, body, "", "  "); err != nil {
+		return nil, fmt.Errorf("invalid JSON response from server: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// GetPostLikers returns the users who liked a post.
+func (c *Client) GetPostLikers(postID int) ([]User, error) {
+	resp, err := c.get(c.endpoint(fmt.Sprintf("/post_action_users.json?id=%d&post_action_type_id=2", postID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch likes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("invalid JSON response from server")
+	}
+
+	var users []User
+	gjson.GetBytes(body, "post_action_users").ForEach(func(_, value gjson.Result) bool {
+		users = append(users, parseUser(value))
+		return true
+	})
+	return users, nil
+}
+
 func (c *Client) PerformPostAction(postID int, postActionTypeID int, flagTopic bool) (*Post, error) {
 	csrfToken, err := c.GetCSRFToken()
 	if err != nil {
@@ -915,7 +1782,7 @@ func (c *Client) PerformPostAction(postID int, postActionTypeID int, flagTopic b
 	data.Set("post_action_type_id", fmt.Sprintf("%d", postActionTypeID))
 	data.Set("flag_topic", fmt.Sprintf("%t", flagTopic))
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/post_actions", c.baseURL), strings.NewReader(data.Encode()))
+	req, err := http.NewRequest("POST", c.endpoint("/post_actions"), strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create post action request: %w", err)
 	}
@@ -925,7 +1792,7 @@ func (c *Client) PerformPostAction(postID int, postActionTypeID int, flagTopic b
 	req.Header.Set("X-Requested-With", "XMLHttpRequest")
 	req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
 
-	resp, err := c.client.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to perform post action: %w", err)
 	}
@@ -942,47 +1809,149 @@ func (c *Client) PerformPostAction(postID int, postActionTypeID int, flagTopic b
 
 	// The response is the updated post object
 	result := gjson.ParseBytes(body)
-	post := Post{
-		ID:         int(result.Get("id").Int()),
-		Name:       result.Get("name").Str,
-		Username:   result.Get("username").Str,
-		CreatedAt:  result.Get("created_at").Time(),
-		Cooked:     result.Get("cooked").Str,
-		PostNumber: int(result.Get("post_number").Int()),
-		ReplyCount: int(result.Get("reply_count").Int()),
-		TopicID:    int(result.Get("topic_id").Int()),
-		TopicSlug:  result.Get("topic_slug").Str,
-		Reads:      int(result.Get("reads").Int()),
-		Score:      result.Get("score").Float(),
-	}
-
-	actionsSummaryResult := result.Get("actions_summary")
-	actionsSummaryResult.ForEach(func(_, actionData gjson.Result) bool {
-		action := ActionsSummary{
-			ID:      int(actionData.Get("id").Int()),
-			Count:   int(actionData.Get("count").Int()),
-			Acted:   actionData.Get("acted").Bool(),
-			CanUndo: actionData.Get("can_undo").Bool(),
-		}
-		post.ActionsSummary = append(post.ActionsSummary, action)
-		return true
-	})
+	post := parsePost(result)
 
 	return &post, nil
 }
 
-func (c *Client) CreateTopic(title, rawContent string, categoryID int, tags []string) (*Post, error) {
+// RemovePostAction undoes a post action of the user, such as a like, and
+// returns the updated post.
+func (c *Client) RemovePostAction(postID int, postActionTypeID int) (*Post, error) {
 	csrfToken, err := c.GetCSRFToken()
 	if err != nil {
-		return nil, fmt.Errorf("failed to get CSRF token for creating topic: %w", err)
+		return nil, fmt.Errorf("failed to get CSRF token for post action: %w", err)
 	}
 
-	payload := apiCreateTopicPayload{
-		Title:     title,
-		Raw:       rawContent,
-		Category:  categoryID,
-		Tags:      tags,
-		Archetype: "regular",
+	endpoint := c.endpoint(fmt.Sprintf("/post_actions/%d?post_action_type_id=%d", postID, postActionTypeID))
+	req, err := http.NewRequest("DELETE", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create post action request: %w", err)
+	}
+
+	req.Header.Set("X-CSRF-Token", csrfToken)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to undo post action: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read post action response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if errs := gjson.GetBytes(body, "errors.0"); errs.Exists() {
+			return nil, fmt.Errorf("undoing post action failed: %s", errs.Str)
+		}
+		return nil, fmt.Errorf("post action API error: %s - %s", resp.Status, string(body))
+	}
+
+	post := parsePost(gjson.ParseBytes(body))
+	return &post, nil
+}
+
+// BookmarkPost bookmarks a single post, with a reminder at reminderAt if
+// it isn't nil.
+func (c *Client) BookmarkPost(postID int, reminderAt *time.Time) error {
+	return c.createBookmark("Post", postID, reminderAt)
+}
+
+// BookmarkTopic bookmarks a whole topic.
+func (c *Client) BookmarkTopic(topicID int) error {
+	return c.createBookmark("Topic", topicID, nil)
+}
+
+// createBookmark bookmarks a post or topic ("Post" or "Topic" bookmarkable).
+func (c *Client) createBookmark(bookmarkable string, id int, reminderAt *time.Time) error {
+	csrfToken, err := c.GetCSRFToken()
+	if err != nil {
+		return fmt.Errorf("failed to get CSRF token for bookmark: %w", err)
+	}
+
+	data := url.Values{}
+	data.Set("bookmarkable_id", fmt.Sprintf("%d", id))
+	data.Set("bookmarkable_type", bookmarkable)
+	if reminderAt != nil {
+		data.Set("reminder_at", reminderAt.UTC().Format(time.RFC3339))
+	}
+
+	req, err := http.NewRequest("POST", c.endpoint("/bookmarks.json"), strings.NewReader(data.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create bookmark request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Set("X-CSRF-Token", csrfToken)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to bookmark post: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		// Discourse explains problems like duplicate bookmarks in "errors"
+		if errs := gjson.GetBytes(body, "errors.0"); errs.Exists() {
+			return fmt.Errorf("bookmark failed: %s", errs.Str)
+		}
+		return fmt.Errorf("bookmark API error: %s - %s", resp.Status, string(body))
+	}
+	return nil
+}
+
+// RemoveBookmark removes the user's bookmarks of a topic, including those of
+// its posts.
+func (c *Client) RemoveBookmark(topicID int) error {
+	csrfToken, err := c.GetCSRFToken()
+	if err != nil {
+		return fmt.Errorf("failed to get CSRF token for bookmark: %w", err)
+	}
+
+	req, err := http.NewRequest("PUT", c.endpoint(fmt.Sprintf("/t/%d/remove_bookmarks.json", topicID)), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create bookmark request: %w", err)
+	}
+
+	req.Header.Set("X-CSRF-Token", csrfToken)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json, text/javascript, */*; q=0.01")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to remove bookmark: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if errs := gjson.GetBytes(body, "errors.0"); errs.Exists() {
+			return fmt.Errorf("removing bookmark failed: %s", errs.Str)
+		}
+		return fmt.Errorf("bookmark API error: %s - %s", resp.Status, string(body))
+	}
+	return nil
+}
+
+func (c *Client) CreateTopic(title, rawContent string, categoryID int, tags []string) (*Post, error) {
+	csrfToken, err := c.GetCSRFToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CSRF token for creating topic: %w", err)
+	}
+
+	rawContent, _ = SanitizeRaw(rawContent)
+	payload := apiCreateTopicPayload{
+		Title:     title,
+		Raw:       rawContent,
+		Category:  categoryID,
+		Tags:      tags,
+		Archetype: "regular",
 	}
 
 	payloadBytes, err := json.Marshal(payload)
@@ -990,7 +1959,7 @@ func (c *Client) CreateTopic(title, rawContent string, categoryID int, tags []st
 		return nil, fmt.Errorf("failed to marshal create topic payload: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/posts.json", c.baseURL), bytes.NewReader(payloadBytes))
+	req, err := http.NewRequest("POST", c.endpoint("/posts.json"), bytes.NewReader(payloadBytes))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create new topic request: %w", err)
 	}
@@ -1000,7 +1969,7 @@ func (c *Client) CreateTopic(title, rawContent string, categoryID int, tags []st
 	req.Header.Set("X-Requested-With", "XMLHttpRequest")
 	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.client.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute create topic request: %w", err)
 	}
@@ -1029,220 +1998,1268 @@ func (c *Client) CreateTopic(title, rawContent string, categoryID int, tags []st
 	return &createdPost, nil
 }
 
-func (c *Client) SetPageCooldown(d time.Duration) {
-	c.pageCooldown = d
-}
-
-func (c *Client) GetMoreTopics(moreURL string) (*Response, error) {
-	if moreURL == "" {
-		return nil, fmt.Errorf("no more topics URL provided")
+// CreatePost replies to a topic, threaded under replyToPostNumber if it isn't 0.
+func (c *Client) CreatePost(topicID int, rawContent string, replyToPostNumber int) (*Post, error) {
+	csrfToken, err := c.GetCSRFToken()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CSRF token for reply: %w", err)
 	}
 
-	var fullURL string
-	if strings.HasPrefix(moreURL, "http") {
-		fullURL = moreURL
-	} else {
-		fullURL = c.baseURL + moreURL
+	rawContent, _ = SanitizeRaw(rawContent)
+	payloadBytes, err := json.Marshal(apiCreatePostPayload{
+		TopicID:           topicID,
+		Raw:               rawContent,
+		ReplyToPostNumber: replyToPostNumber,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal reply payload: %w", err)
 	}
 
-	resp, err := c.client.Get(fullURL)
+	req, err := http.NewRequest("POST", c.endpoint("/posts.json"), bytes.NewReader(payloadBytes))
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch more topics: %v", err)
+		return nil, fmt.Errorf("failed to create reply request: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-CSRF-Token", csrfToken)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute reply request: %w", err)
 	}
+	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("failed to read reply response body: %w", err)
 	}
 
-	result := gjson.ParseBytes(body)
-	response := &Response{}
+	if resp.StatusCode == http.StatusUnprocessableEntity && !gjson.GetBytes(body, "errors.0").Exists() {
+		return nil, fmt.Errorf("reply failed: the topic is closed or no longer accepts replies")
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		if errs := gjson.GetBytes(body, "errors.0"); errs.Exists() {
+			return nil, fmt.Errorf("reply failed: %s", errs.Str)
+		}
+		return nil, fmt.Errorf("reply API error: %s - %s", resp.Status, string(body))
+	}
 
-	users := result.Get("users")
-	users.ForEach(func(_, value gjson.Result) bool {
-		user := User{
-			ID:             int(value.Get("id").Int()),
-			Username:       value.Get("username").Str,
-			Name:           value.Get("name").Str,
-			AvatarTemplate: value.Get("avatar_template").Str,
-			TrustLevel:     int(value.Get("trust_level").Int()),
-			Moderator:      value.Get("moderator").Bool(),
-		}
-		response.Users = append(response.Users, user)
-		return true
-	})
+	post := parsePost(gjson.ParseBytes(body))
+	if post.ID == 0 {
+		return nil, fmt.Errorf("created post has ID 0, which is invalid (body: %s)", string(body))
+	}
+	return &post, nil
+}
 
-	topicList := result.Get("topic_list")
-	response.TopicList.CanCreateTopic = topicList.Get("can_create_topic").Bool()
-	response.TopicList.MoreTopicsURL = topicList.Get("more_topics_url").Str
-	response.TopicList.PerPage = int(topicList.Get("per_page").Int())
+// UpdateTopic changes a topic's title, category and tags; a categoryID of 0
+// leaves the category as it is. The forum decides who may: usually the
+// topic's author for a while, and staff.
+func (c *Client) UpdateTopic(topicID int, title string, categoryID int, tags []string) error {
+	csrfToken, err := c.GetCSRFToken()
+	if err != nil {
+		return fmt.Errorf("failed to get CSRF token for topic update: %w", err)
+	}
 
-	topics := topicList.Get("topics")
-	topics.ForEach(func(_, value gjson.Result) bool {
-		topic := Topic{
-			ID:                 int(value.Get("id").Int()),
-			Title:              value.Get("title").Str,
-			FancyTitle:         value.Get("fancy_title").Str,
-			Slug:               value.Get("slug").Str,
-			PostsCount:         int(value.Get("posts_count").Int()),
-			ReplyCount:         int(value.Get("reply_count").Int()),
-			HighestPostNumber:  int(value.Get("highest_post_number").Int()),
-			ImageURL:           value.Get("image_url").Str,
-			CreatedAt:          value.Get("created_at").Time(),
-			LastPostedAt:       value.Get("last_posted_at").Time(),
-			Bumped:             value.Get("bumped").Bool(),
-			BumpedAt:           value.Get("bumped_at").Time(),
-			Archetype:          value.Get("archetype").Str,
-			Unseen:             value.Get("unseen").Bool(),
-			LastReadPostNumber: int(value.Get("last_read_post_number").Int()),
-			Unread:             int(value.Get("unread").Int()),
-			NewPosts:           int(value.Get("new_posts").Int()),
-			UnreadPosts:        int(value.Get("unread_posts").Int()),
-			Pinned:             value.Get("pinned").Bool(),
-			Visible:            value.Get("visible").Bool(),
-			Closed:             value.Get("closed").Bool(),
-			Archived:           value.Get("archived").Bool(),
-			NotificationLevel:  int(value.Get("notification_level").Int()),
-			Bookmarked:         value.Get("bookmarked").Bool(),
-			Liked:              value.Get("liked").Bool(),
-			Views:              int(value.Get("views").Int()),
-			LikeCount:          int(value.Get("like_count").Int()),
-			LastPosterUsername: value.Get("last_poster_username").Str,
-			CategoryID:         int(value.Get("category_id").Int()),
-		}
+	if tags == nil {
+		tags = []string{}
+	}
+	payloadBytes, err := json.Marshal(apiUpdateTopicPayload{
+		Title:      title,
+		CategoryID: categoryID,
+		Tags:       tags,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal topic update payload: %w", err)
+	}
 
-		tags := value.Get("tags")
-		tags.ForEach(func(_, tag gjson.Result) bool {
-			topic.Tags = append(topic.Tags, tag.Str)
-			return true
-		})
+	req, err := http.NewRequest("PUT", c.endpoint(fmt.Sprintf("/t/-/%d.json", topicID)), bytes.NewReader(payloadBytes))
+	if err != nil {
+		return fmt.Errorf("failed to create topic update request: %w", err)
+	}
 
-		response.TopicList.Topics = append(response.TopicList.Topics, topic)
-		return true
-	})
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-CSRF-Token", csrfToken)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
 
-	categories, err := c.GetCategories()
+	resp, err := c.doRequest(req)
 	if err != nil {
-		log.Printf("Warning: failed to fetch categories: %v", err)
-	} else {
-		categoryMap := make(map[int]struct {
-			Name  string
-			Color string
-		})
-		for _, category := range categories.CategoryList.Categories {
-			categoryMap[category.ID] = struct {
-				Name  string
-				Color string
-			}{
-				Name:  category.Name,
-				Color: category.Color,
-			}
-		}
+		return fmt.Errorf("failed to update topic: %w", err)
+	}
+	defer resp.Body.Close()
 
-		for i := range response.TopicList.Topics {
-			if cat, ok := categoryMap[response.TopicList.Topics[i].CategoryID]; ok {
-				response.TopicList.Topics[i].CategoryName = cat.Name
-				response.TopicList.Topics[i].CategoryColor = cat.Color
-			}
+	if resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("you don't have permission to edit this topic")
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var messages []string
+		gjson.GetBytes(body, "errors").ForEach(func(_, value gjson.Result) bool {
+			messages = append(messages, value.String())
+			return true
+		})
+		if len(messages) > 0 {
+			return fmt.Errorf("topic update failed: %s", strings.Join(messages, "; "))
 		}
+		return fmt.Errorf("topic update API error: %s - %s", resp.Status, string(body))
 	}
-
-	return response, nil
+	return nil
 }
 
-func (c *Client) LoadAllTopics(maxPages int) (*Response, error) {
-	if maxPages <= 0 {
-		maxPages = 10
-	}
+func (c *Client) SetPageCooldown(d time.Duration) {
+	c.pageCooldown = d
+}
 
-	initialResp, err := c.GetLatestTopics()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get initial topics: %v", err)
-	}
+func (c *Client) PageCooldown() time.Duration {
+	return c.pageCooldown
+}
 
-	allTopics := initialResp.TopicList.Topics
-	allUsers := initialResp.Users
-	currentMoreURL := initialResp.TopicList.MoreTopicsURL
+// waitCooldown sleeps for the page cooldown, returning ctx's error early if it
+// is cancelled first.
+func (c *Client) waitCooldown(ctx context.Context) error {
+	c.coolingDown.Store(true)
+	defer c.coolingDown.Store(false)
 
-	for page := 1; page < maxPages && currentMoreURL != ""; page++ {
-		time.Sleep(c.pageCooldown)
+	timer := time.NewTimer(c.pageCooldown)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
 
-		moreResp, err := c.GetMoreTopics(currentMoreURL)
-		if err != nil {
-			log.Printf("Warning: failed to fetch page %d: %v", page+1, err)
-			break
-		}
+// CoolingDown reports whether a bulk fetch is currently waiting out the page
+// cooldown.
+func (c *Client) CoolingDown() bool {
+	return c.coolingDown.Load()
+}
 
-		allTopics = append(allTopics, moreResp.TopicList.Topics...)
-		allUsers = append(allUsers, moreResp.Users...)
-		currentMoreURL = moreResp.TopicList.MoreTopicsURL
+const (
+	DefaultPostBatchSize = 100
+	// MaxPostBatchSize keeps the post_ids[] query string under the 8KB URI
+	// limit of common servers: each encoded "post_ids%5B%5D=<id>&" takes up
+	// to about 25 bytes, so 250 IDs need some 6KB.
+	MaxPostBatchSize = 250
+)
 
-		if len(moreResp.TopicList.Topics) == 0 {
-			break
-		}
+// SetMaxRetries sets how many times a request answered with 429 Too Many
+// Requests is retried before failing with ErrRateLimited; 0 disables retries.
+func (c *Client) SetMaxRetries(n int) error {
+	if n < 0 {
+		return fmt.Errorf("max retries can't be negative, got %d", n)
 	}
+	c.maxRetries = n
+	return nil
+}
 
-	result := &Response{
-		Users:         allUsers,
-		PrimaryGroups: initialResp.PrimaryGroups,
-		FlairGroups:   initialResp.FlairGroups,
-		TopicList: TopicList{
-			CanCreateTopic: initialResp.TopicList.CanCreateTopic,
-			MoreTopicsURL:  currentMoreURL,
-			PerPage:        initialResp.TopicList.PerPage,
-			TopTags:        initialResp.TopicList.TopTags,
-			Topics:         allTopics,
-		},
+// SetPostBatchSize sets how many posts GetTopicPosts requests at once.
+// Values above MaxPostBatchSize are clamped.
+func (c *Client) SetPostBatchSize(n int) error {
+	if n <= 0 {
+		return fmt.Errorf("post batch size must be positive, got %d", n)
+	}
+	if n > MaxPostBatchSize {
+		log.Printf("Post batch size %d is too large, using %d", n, MaxPostBatchSize)
+		n = MaxPostBatchSize
 	}
+	c.postBatchSize = n
+	return nil
+}
 
-	return result, nil
+// SetTimeout bounds each request, including reading its response body; 0
+// means no limit. It applies per request, so LoadAllTopics, which makes a
+// request per page, is limited by SetLoadAllTimeout instead.
+func (c *Client) SetTimeout(d time.Duration) {
+	c.client.Timeout = d
 }
 
-func (c *Client) Search(query string) (*SearchResponse, error) {
-	if query == "" {
-		return nil, fmt.Errorf("search query cannot be empty")
-	}
+// SetLoadAllTimeout caps how long LoadAllTopics keeps paging; 0 means no limit.
+func (c *Client) SetLoadAllTimeout(d time.Duration) {
+	c.loadAllTimeout = d
+}
 
-	// URL encode the query
-	encodedQuery := url.QueryEscape(query)
-	searchURL := fmt.Sprintf("%s/search/query?term=%s", c.baseURL, encodedQuery)
+// GetMoreTopics fetches the next page of whichever list produced moreURL.
+// Discourse hands out the HTML route (e.g. /c/general/4/l/latest?page=1), so
+// the path is rewritten to its .json form; the returned MoreTopicsURL then
+// continues paging within that same latest/category/top view.
+func (c *Client) GetMoreTopics(moreURL string) (*Response, error) {
+	if moreURL == "" {
+		return nil, fmt.Errorf("no more topics URL provided")
+	}
+	if query, ok := strings.CutPrefix(moreURL, "/search.json?"); ok {
+		params, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid more search results URL %q: %w", moreURL, err)
+		}
+		page, _ := strconv.Atoi(params.Get("page"))
+		return c.searchTopicsPage(params.Get("q"), page)
+	}
 
-	req, err := http.NewRequest("GET", searchURL, nil)
+	fullURL, err := c.topicListURL(moreURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create search request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("accept", "application/json, text/javascript, */*; q=0.01")
-	req.Header.Set("discourse-present", "true")
-	req.Header.Set("x-requested-with", "XMLHttpRequest")
-	req.Header.Set("user-agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36")
+	req, err := http.NewRequest("GET", fullURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create more topics request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
 
-	resp, err := c.client.Do(req)
+	resp, err := c.doRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute search request: %w", err)
+		return nil, fmt.Errorf("failed to fetch more topics: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("search API error: %s - %s", resp.Status, string(body))
+		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read search response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	var searchResponse SearchResponse
-	if err := json.Unmarshal(body, &searchResponse); err != nil {
-		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	response, err := parseTopicList(body)
+	if err != nil {
+		return nil, err
 	}
+	c.EnrichTopicCategories(response.TopicList.Topics)
 
-	return &searchResponse, nil
+	return response, nil
+}
+
+// GetHotTopics returns the topics of Discourse's ranked "hot" view, or
+// ErrHotUnavailable when the forum doesn't have it.
+func (c *Client) GetHotTopics() (*Response, error) {
+	resp, err := c.get(c.endpoint("/hot.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch hot topics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrHotUnavailable
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	response, err := parseTopicList(body)
+	if err != nil {
+		return nil, err
+	}
+	c.EnrichTopicCategories(response.TopicList.Topics)
+
+	return response, nil
+}
+
+// TopicSorts are the topic lists GetTopics accepts. new and unread need a
+// logged in user.
+var TopicSorts = []string{"latest", "top", "new", "unread", "hot"}
+
+// GetTopics returns the first page of one of the forum's topic lists, named
+// as in TopicSorts.
+func (c *Client) GetTopics(sort string) (*Response, error) {
+	switch sort {
+	case "latest":
+		return c.GetLatestTopics()
+	case "hot":
+		return c.GetHotTopics()
+	case "top", "new", "unread":
+	default:
+		return nil, fmt.Errorf("unknown topic list %q", sort)
+	}
+
+	resp, err := c.get(c.endpoint("/" + sort + ".json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s topics: %w", sort, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	response, err := parseTopicList(body)
+	if err != nil {
+		return nil, err
+	}
+	c.EnrichTopicCategories(response.TopicList.Topics)
+
+	return response, nil
+}
+
+// GetCategoryTopics returns the latest topics in a category and its
+// subcategories. The slug is looked up when it is empty.
+func (c *Client) GetCategoryTopics(categoryID int, slug string) (*Response, error) {
+	path := fmt.Sprintf("/c/%d.json", categoryID)
+	if slug != "" {
+		path = fmt.Sprintf("/c/%s/%d.json", url.PathEscape(slug), categoryID)
+	} else if categories, err := c.GetCategories(); err == nil {
+		for _, category := range categories.CategoryList.Categories {
+			if category.ID == categoryID && category.Slug != "" {
+				path = fmt.Sprintf("/c/%s/%d.json", url.PathEscape(category.Slug), categoryID)
+				break
+			}
+		}
+	}
+
+	resp, err := c.get(c.endpoint(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch category topics: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	response, err := parseTopicList(body)
+	if err != nil {
+		return nil, err
+	}
+	c.EnrichTopicCategories(response.TopicList.Topics)
+
+	return response, nil
+}
+
+func (c *Client) topicListURL(moreURL string) (string, error) {
+	u, err := url.Parse(moreURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid more topics URL %q: %w", moreURL, err)
+	}
+	if !strings.HasSuffix(u.Path, ".json") {
+		u.Path = strings.TrimSuffix(u.Path, "/") + ".json"
+	}
+	if u.IsAbs() {
+		return u.String(), nil
+	}
+	if !strings.HasPrefix(u.Path, "/") {
+		u.Path = "/" + u.Path
+	}
+	if c.pathPrefix != "" && !strings.HasPrefix(u.Path, c.pathPrefix+"/") {
+		u.Path = c.pathPrefix + u.Path
+	}
+	return c.baseURL + u.String(), nil
+}
+
+// parseTopicList parses any topic list response (latest, category, top, ...).
+func parseTopicList(body []byte) (*Response, error) {
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("invalid JSON response from server")
+	}
+
+	result := gjson.ParseBytes(body)
+	response := &Response{}
+
+	users := result.Get("users")
+	users.ForEach(func(_, value gjson.Result) bool {
+		response.Users = append(response.Users, parseUser(value))
+		return true
+	})
+
+	topicList := result.Get("topic_list")
+	response.TopicList.CanCreateTopic = topicList.Get("can_create_topic").Bool()
+	response.TopicList.MoreTopicsURL = topicList.Get("more_topics_url").Str
+	response.TopicList.PerPage = int(topicList.Get("per_page").Int())
+
+	topicList.Get("top_tags").ForEach(func(_, tag gjson.Result) bool {
+		response.TopicList.TopTags = append(response.TopicList.TopTags, tag.Str)
+		return true
+	})
+
+	usernames := make(map[int]string, len(response.Users))
+	for _, user := range response.Users {
+		usernames[user.ID] = user.Username
+	}
+
+	topics := topicList.Get("topics")
+	topics.ForEach(func(_, value gjson.Result) bool {
+		topic := parseTopic(value)
+		for _, poster := range topic.Posters {
+			if strings.Contains(poster.Description, "Original Poster") {
+				topic.CreatorUsername = usernames[poster.UserID]
+				break
+			}
+		}
+		response.TopicList.Topics = append(response.TopicList.Topics, topic)
+		return true
+	})
+
+	return response, nil
+}
+
+func parseUser(value gjson.Result) User {
+	return User{
+		ID:             int(value.Get("id").Int()),
+		Username:       value.Get("username").Str,
+		Name:           value.Get("name").Str,
+		AvatarTemplate: value.Get("avatar_template").Str,
+		TrustLevel:     int(value.Get("trust_level").Int()),
+		Moderator:      value.Get("moderator").Bool(),
+	}
+}
+
+func parseTopic(value gjson.Result) Topic {
+	topic := Topic{
+		ID:                 int(value.Get("id").Int()),
+		Title:              value.Get("title").Str,
+		FancyTitle:         value.Get("fancy_title").Str,
+		Slug:               value.Get("slug").Str,
+		PostsCount:         int(value.Get("posts_count").Int()),
+		ReplyCount:         int(value.Get("reply_count").Int()),
+		HighestPostNumber:  int(value.Get("highest_post_number").Int()),
+		ImageURL:           value.Get("image_url").Str,
+		CreatedAt:          value.Get("created_at").Time(),
+		LastPostedAt:       value.Get("last_posted_at").Time(),
+		Bumped:             value.Get("bumped").Bool(),
+		BumpedAt:           value.Get("bumped_at").Time(),
+		Archetype:          value.Get("archetype").Str,
+		Unseen:             value.Get("unseen").Bool(),
+		LastReadPostNumber: int(value.Get("last_read_post_number").Int()),
+		Unread:             int(value.Get("unread").Int()),
+		NewPosts:           int(value.Get("new_posts").Int()),
+		UnreadPosts:        int(value.Get("unread_posts").Int()),
+		Pinned:             value.Get("pinned").Bool(),
+		PinnedGlobally:     value.Get("pinned_globally").Bool(),
+		HasAcceptedAnswer:  value.Get("has_accepted_answer").Bool(),
+		Visible:            value.Get("visible").Bool(),
+		Closed:             value.Get("closed").Bool(),
+		Archived:           value.Get("archived").Bool(),
+		NotificationLevel:  int(value.Get("notification_level").Int()),
+		Bookmarked:         value.Get("bookmarked").Bool(),
+		Liked:              value.Get("liked").Bool(),
+		Views:              int(value.Get("views").Int()),
+		LikeCount:          int(value.Get("like_count").Int()),
+		LastPosterUsername: value.Get("last_poster_username").Str,
+		CategoryID:         int(value.Get("category_id").Int()),
+		// Only topic views have details; lists fill it from the posters
+		CreatorUsername: value.Get("details.created_by.username").Str,
+	}
+
+	tags := value.Get("tags")
+	tags.ForEach(func(_, tag gjson.Result) bool {
+		// Newer topic views send tags as objects
+		if tag.IsObject() {
+			topic.Tags = append(topic.Tags, tag.Get("name").Str)
+		} else {
+			topic.Tags = append(topic.Tags, tag.Str)
+		}
+		return true
+	})
+
+	value.Get("posters").ForEach(func(_, poster gjson.Result) bool {
+		topic.Posters = append(topic.Posters, Poster{
+			UserID:      int(poster.Get("user_id").Int()),
+			Description: poster.Get("description").Str,
+		})
+		return true
+	})
+
+	return topic
+}
+
+// EnrichTopicCategories fills in CategoryName and CategoryColor from the
+// (cached) category list. Topics whose category isn't listed, e.g. restricted
+// subcategories, are left without a name.
+func (c *Client) EnrichTopicCategories(topics []Topic) {
+	categories, err := c.GetCategories()
+	if err != nil {
+		log.Printf("Warning: failed to fetch categories: %v", err)
+		return
+	}
+
+	categoryMap := make(map[int]Category)
+	for _, category := range categories.CategoryList.Categories {
+		categoryMap[category.ID] = category
+	}
+
+	for i := range topics {
+		if cat, ok := categoryMap[topics[i].CategoryID]; ok {
+			topics[i].CategoryName = cat.Name
+			topics[i].CategoryColor = cat.Color
+		}
+	}
+}
+
+func (c *Client) LoadAllTopics(maxPages int) (*Response, error) {
+	return c.LoadAllTopicsContext(context.Background(), maxPages)
+}
+
+// LoadAllTopicsContext is LoadAllTopics, but returns ctx's error as soon as
+// ctx is cancelled instead of waiting out the current cooldown.
+func (c *Client) LoadAllTopicsContext(ctx context.Context, maxPages int) (*Response, error) {
+	if maxPages <= 0 {
+		maxPages = 10
+	}
+	start := time.Now()
+
+	initialResp, err := c.GetLatestTopics()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get initial topics: %w", err)
+	}
+
+	allTopics := initialResp.TopicList.Topics
+	allUsers := initialResp.Users
+	currentMoreURL := initialResp.TopicList.MoreTopicsURL
+
+	var deadline time.Time
+	if c.loadAllTimeout > 0 {
+		deadline = start.Add(c.loadAllTimeout)
+	}
+
+	pages := 1
+	stopReason := "no more pages"
+	for currentMoreURL != "" {
+		if pages >= maxPages {
+			stopReason = fmt.Sprintf("page limit of %d reached", maxPages)
+			break
+		}
+		if !deadline.IsZero() && time.Now().Add(c.pageCooldown).After(deadline) {
+			stopReason = fmt.Sprintf("time budget of %s exceeded", c.loadAllTimeout)
+			break
+		}
+		if err := c.waitCooldown(ctx); err != nil {
+			log.Printf("Load all cancelled after %d pages", pages)
+			return nil, err
+		}
+
+		moreResp, err := c.GetMoreTopics(currentMoreURL)
+		if err != nil {
+			stopReason = fmt.Sprintf("failed to fetch page %d: %v", pages+1, err)
+			break
+		}
+		pages++
+
+		allTopics = append(allTopics, moreResp.TopicList.Topics...)
+		allUsers = append(allUsers, moreResp.Users...)
+		currentMoreURL = moreResp.TopicList.MoreTopicsURL
+
+		if len(moreResp.TopicList.Topics) == 0 {
+			stopReason = "empty page"
+			break
+		}
+	}
+	log.Printf("Load all stopped after %d pages in %s: %s", pages, time.Since(start).Round(time.Millisecond), stopReason)
+
+	result := &Response{
+		Users:         allUsers,
+		PrimaryGroups: initialResp.PrimaryGroups,
+		FlairGroups:   initialResp.FlairGroups,
+		TopicList: TopicList{
+			CanCreateTopic: initialResp.TopicList.CanCreateTopic,
+			MoreTopicsURL:  currentMoreURL,
+			PerPage:        initialResp.TopicList.PerPage,
+			TopTags:        initialResp.TopicList.TopTags,
+			Topics:         allTopics,
+		},
+	}
+
+	return result, nil
+}
+
+func (c *Client) Search(query string) (*SearchResponse, error) {
+	if query == "" {
+		return nil, fmt.Errorf("search query cannot be empty")
+	}
+
+	// URL encode the query
+	encodedQuery := url.QueryEscape(query)
+	searchURL := c.endpoint(fmt.Sprintf("/search/query?term=%s", encodedQuery))
+
+	req, err := http.NewRequest("GET", searchURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create search request: %w", err)
+	}
+
+	req.Header.Set("accept", "application/json, text/javascript, */*; q=0.01")
+	req.Header.Set("discourse-present", "true")
+	req.Header.Set("x-requested-with", "XMLHttpRequest")
+	req.Header.Set("user-agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/136.0.0.0 Safari/537.36")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("search API error: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search response body: %w", err)
+	}
+
+	var searchResponse SearchResponse
+	if err := json.Unmarshal(body, &searchResponse); err != nil {
+		return nil, fmt.Errorf("failed to parse search response: %w", err)
+	}
+
+	return &searchResponse, nil
+}
+
+// SearchTopics runs a full-page search of the forum and returns the matching
+// topics, most relevant first. When there are more results MoreTopicsURL is
+// set, and GetMoreTopics fetches the next page. An empty query returns the
+// latest topics.
+func (c *Client) SearchTopics(query string) (*Response, error) {
+	if strings.TrimSpace(query) == "" {
+		return c.GetLatestTopics()
+	}
+	return c.searchTopicsPage(query, 1)
+}
+
+func (c *Client) searchTopicsPage(query string, page int) (*Response, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	if page > 1 {
+		params.Set("page", strconv.Itoa(page))
+	}
+	resp, err := c.get(c.endpoint("/search.json?" + params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute search request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("search API error: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read search response body: %w", err)
+	}
+
+	response, err := parseSearchTopics(body, query, max(page, 1))
+	if err != nil {
+		return nil, err
+	}
+	c.EnrichTopicCategories(response.TopicList.Topics)
+	return response, nil
+}
+
+// parseSearchTopics turns a /search.json page into a topic list. Posts come
+// in relevance order, so their topics are listed first, followed by topics
+// that matched on their title alone.
+func parseSearchTopics(body []byte, query string, page int) (*Response, error) {
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("invalid JSON response from server")
+	}
+	result := gjson.ParseBytes(body)
+
+	topics := make(map[int]Topic)
+	var order []int
+	result.Get("topics").ForEach(func(_, value gjson.Result) bool {
+		topic := parseTopic(value)
+		if _, ok := topics[topic.ID]; !ok {
+			order = append(order, topic.ID)
+		}
+		topics[topic.ID] = topic
+		return true
+	})
+
+	response := &Response{}
+	seen := make(map[int]bool, len(topics))
+	add := func(topicID int) {
+		if topic, ok := topics[topicID]; ok && !seen[topicID] {
+			seen[topicID] = true
+			response.TopicList.Topics = append(response.TopicList.Topics, topic)
+		}
+	}
+	result.Get("posts").ForEach(func(_, value gjson.Result) bool {
+		add(int(value.Get("topic_id").Int()))
+		return true
+	})
+	for _, topicID := range order {
+		add(topicID)
+	}
+
+	if result.Get("grouped_search_result.more_full_page_results").Bool() {
+		next := url.Values{}
+		next.Set("q", query)
+		next.Set("page", strconv.Itoa(page+1))
+		response.TopicList.MoreTopicsURL = "/search.json?" + next.Encode()
+	}
+	return response, nil
+}
+
+func (c *Client) GetUser(username string) (*UserProfile, error) {
+	if username == "" {
+		return nil, fmt.Errorf("username cannot be empty")
+	}
+
+	resp, err := c.get(c.endpoint(fmt.Sprintf("/u/%s.json", url.PathEscape(username))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch user %s: %w", username, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrUserNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("user API error: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user response body: %w", err)
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("invalid JSON response from server")
+	}
+
+	user := gjson.GetBytes(body, "user")
+	profile := &UserProfile{
+		ID:              int(user.Get("id").Int()),
+		Username:        user.Get("username").Str,
+		Name:            user.Get("name").Str,
+		AvatarTemplate:  user.Get("avatar_template").Str,
+		TrustLevel:      int(user.Get("trust_level").Int()),
+		Moderator:       user.Get("moderator").Bool(),
+		Admin:           user.Get("admin").Bool(),
+		Bio:             user.Get("bio_raw").Str,
+		BioExcerpt:      user.Get("bio_excerpt").Str,
+		CreatedAt:       user.Get("created_at").Time(),
+		PostCount:       int(user.Get("post_count").Int()),
+		BadgeCount:      int(user.Get("badge_count").Int()),
+		FollowSupported: user.Get("can_follow").Exists(),
+		CanFollow:       user.Get("can_follow").Bool(),
+		IsFollowed:      user.Get("is_followed").Bool(),
+		TotalFollowers:  int(user.Get("total_followers").Int()),
+		TotalFollowing:  int(user.Get("total_following").Int()),
+	}
+
+	return profile, nil
+}
+
+// GetUserActivity returns the topics a user recently started and the replies
+// they recently posted, newest first.
+func (c *Client) GetUserActivity(username string) ([]UserAction, error) {
+	if username == "" {
+		return nil, fmt.Errorf("username cannot be empty")
+	}
+
+	query := url.Values{}
+	query.Set("username", username)
+	query.Set("filter", fmt.Sprintf("%d,%d", UserActionNewTopic, UserActionReply))
+	resp, err := c.get(c.endpoint("/user_actions.json?" + query.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch activity of %s: %w", username, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		return nil, ErrActivityHidden
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("user activity API error: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user activity response body: %w", err)
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("invalid JSON response from server")
+	}
+
+	var actions []UserAction
+	gjson.GetBytes(body, "user_actions").ForEach(func(_, value gjson.Result) bool {
+		actions = append(actions, UserAction{
+			ActionType: int(value.Get("action_type").Int()),
+			TopicID:    int(value.Get("topic_id").Int()),
+			PostID:     int(value.Get("post_id").Int()),
+			PostNumber: int(value.Get("post_number").Int()),
+			Title:      value.Get("title").Str,
+			Slug:       value.Get("slug").Str,
+			Excerpt:    value.Get("excerpt").Str,
+			CategoryID: int(value.Get("category_id").Int()),
+			CreatedAt:  value.Get("created_at").Time(),
+		})
+		return true
+	})
+	return actions, nil
+}
+
+// GetNotifications returns the current user's recent notifications, newest
+// first.
+func (c *Client) GetNotifications() ([]Notification, error) {
+	resp, err := c.get(c.endpoint("/notifications.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch notifications: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("notifications API error: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notifications response body: %w", err)
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("invalid JSON response from server")
+	}
+
+	var notifications []Notification
+	gjson.GetBytes(body, "notifications").ForEach(func(_, value gjson.Result) bool {
+		title := html.UnescapeString(value.Get("fancy_title").Str)
+		if title == "" {
+			title = value.Get("data.topic_title").Str
+		}
+		if title == "" {
+			title = value.Get("data.badge_name").Str
+		}
+		username := value.Get("data.display_username").Str
+		if username == "" {
+			username = value.Get("data.original_username").Str
+		}
+		notifications = append(notifications, Notification{
+			ID:               int(value.Get("id").Int()),
+			NotificationType: int(value.Get("notification_type").Int()),
+			Read:             value.Get("read").Bool(),
+			CreatedAt:        value.Get("created_at").Time(),
+			TopicID:          int(value.Get("topic_id").Int()),
+			PostNumber:       int(value.Get("post_number").Int()),
+			Title:            title,
+			Username:         username,
+		})
+		return true
+	})
+	return notifications, nil
+}
+
+func (c *Client) FollowUser(username string) error {
+	return c.setFollowing(username, true)
+}
+
+func (c *Client) UnfollowUser(username string) error {
+	return c.setFollowing(username, false)
+}
+
+// setFollowing uses the discourse-follow plugin endpoint, which takes PUT to
+// follow and DELETE to unfollow.
+func (c *Client) setFollowing(username string, follow bool) error {
+	if username == "" {
+		return fmt.Errorf("username cannot be empty")
+	}
+
+	csrfToken, err := c.GetCSRFToken()
+	if err != nil {
+		return fmt.Errorf("failed to get CSRF token for follow: %w", err)
+	}
+
+	method := http.MethodPut
+	if !follow {
+		method = http.MethodDelete
+	}
+
+	req, err := http.NewRequest(method, c.endpoint(fmt.Sprintf("/follow/%s.json", url.PathEscape(username))), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create follow request: %w", err)
+	}
+
+	req.Header.Set("X-CSRF-Token", csrfToken)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to update follow state: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return fmt.Errorf("this instance does not support following users")
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("follow API error: %s - %s", resp.Status, string(body))
+	}
+
+	return nil
+}
+
+// GetCurrentUser returns the logged-in user from /session/current.json. It
+// fails when there is no valid session (e.g. in unauthenticated mode).
+func (c *Client) GetCurrentUser() (*UserProfile, error) {
+	req, err := http.NewRequest("GET", c.endpoint("/session/current.json"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create current user request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch current user: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrSessionExpired
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("current user API error: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current user response body: %w", err)
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("invalid JSON response from server")
+	}
+
+	user := gjson.GetBytes(body, "current_user")
+	if !user.Exists() {
+		return nil, ErrSessionExpired
+	}
+
+	return &UserProfile{
+		ID:             int(user.Get("id").Int()),
+		Username:       user.Get("username").Str,
+		Name:           user.Get("name").Str,
+		AvatarTemplate: user.Get("avatar_template").Str,
+		TrustLevel:     int(user.Get("trust_level").Int()),
+		Moderator:      user.Get("moderator").Bool(),
+		Admin:          user.Get("admin").Bool(),
+	}, nil
+}
+
+func (c *Client) SetTopicClosed(topicID int, closed bool) error {
+	return c.setTopicStatus(topicID, "closed", closed)
+}
+
+func (c *Client) SetTopicArchived(topicID int, archived bool) error {
+	return c.setTopicStatus(topicID, "archived", archived)
+}
+
+func (c *Client) setTopicStatus(topicID int, status string, enabled bool) error {
+	csrfToken, err := c.GetCSRFToken()
+	if err != nil {
+		return fmt.Errorf("failed to get CSRF token for topic status: %w", err)
+	}
+
+	data := url.Values{}
+	data.Set("status", status)
+	data.Set("enabled", fmt.Sprintf("%t", enabled))
+
+	req, err := http.NewRequest("PUT", c.endpoint(fmt.Sprintf("/t/%d/status", topicID)), strings.NewReader(data.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create topic status request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Set("X-CSRF-Token", csrfToken)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to update topic status: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("you don't have permission to change this topic's %s status", status)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("topic status API error: %s - %s", resp.Status, string(body))
+	}
+
+	return nil
+}
+
+// MarkTopicUnread makes every post of a topic from postNumber on unread for
+// the current user. Discourse can only forget a topic's read state as a
+// whole, so it is reset and the posts before postNumber are read again.
+func (c *Client) MarkTopicUnread(topicID, postNumber int) error {
+	csrfToken, err := c.GetCSRFToken()
+	if err != nil {
+		return fmt.Errorf("failed to get CSRF token for marking unread: %w", err)
+	}
+
+	req, err := http.NewRequest("DELETE", c.endpoint(fmt.Sprintf("/t/%d/timings.json", topicID)), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create mark unread request: %w", err)
+	}
+	req.Header.Set("X-CSRF-Token", csrfToken)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return fmt.Errorf("failed to mark topic unread: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("marking topics unread requires login")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("mark unread API error: %s", resp.Status)
+	}
+
+	if postNumber <= 1 {
+		return nil
+	}
+	if err := c.postTimings(csrfToken, topicID, 1, postNumber-1); err != nil {
+		return fmt.Errorf("failed to mark earlier posts read: %w", err)
+	}
+	return nil
+}
+
+// MarkTopicRead tells the forum the posts of a topic from through were
+// read, the way the web UI reports read time.
+func (c *Client) MarkTopicRead(topicID, from, through int) error {
+	from = max(from, 1)
+	if through < from {
+		return nil
+	}
+	csrfToken, err := c.GetCSRFToken()
+	if err != nil {
+		return fmt.Errorf("failed to get CSRF token for marking read: %w", err)
+	}
+	if err := c.postTimings(csrfToken, topicID, from, through); err != nil {
+		return fmt.Errorf("failed to mark topic read: %w", err)
+	}
+	return nil
+}
+
+// maxTimingsBatch caps the posts reported in one timings request.
+const maxTimingsBatch = 100
+
+// postTimings reports a second of reading time for posts from to through,
+// maxTimingsBatch posts per request.
+func (c *Client) postTimings(csrfToken string, topicID, from, through int) error {
+	for start := from; start <= through; start += maxTimingsBatch {
+		if err := c.postTimingsBatch(csrfToken, topicID, start, min(start+maxTimingsBatch-1, through)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Client) postTimingsBatch(csrfToken string, topicID, from, through int) error {
+	data := url.Values{}
+	data.Set("topic_id", strconv.Itoa(topicID))
+	data.Set("topic_time", "1000")
+	for n := from; n <= through; n++ {
+		data.Set(fmt.Sprintf("timings[%d]", n), "1000")
+	}
+	req, err := http.NewRequest("POST", c.endpoint("/topics/timings"), strings.NewReader(data.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create timings request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Set("X-CSRF-Token", csrfToken)
+	req.Header.Set("X-Requested-With", "XMLHttpRequest")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("timings API error: %s - %s", resp.Status, string(body))
+	}
+	return nil
+}
+
+// InstanceCacheDir returns the directory the client caches data for the
+// instance at baseURL under.
+func InstanceCacheDir(baseURL string) (string, error) {
+	userCacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get cache directory: %w", err)
+	}
+	name := strings.TrimSuffix(strings.TrimSpace(baseURL), "/")
+	name = strings.TrimPrefix(strings.TrimPrefix(name, "https://"), "http://")
+	return filepath.Join(userCacheDir, "discourse-tui-client", "instances", name), nil
+}
+
+func (c *Client) instanceCacheDir() (string, error) {
+	return InstanceCacheDir(c.baseURL)
+}
+
+func (c *Client) topicCachePath(topicID int) (string, error) {
+	instanceDir, err := c.instanceCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(instanceDir, "topics", fmt.Sprintf("%d.json", topicID)), nil
+}
+
+func (c *Client) cacheTopicPosts(topicID int, posts *TopicResponse) {
+	cachePath, err := c.topicCachePath(topicID)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	data, err := json.Marshal(posts)
+	if err != nil {
+		log.Printf("Warning: failed to marshal posts for topic %d: %v", topicID, err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0750); err != nil {
+		log.Printf("Warning: failed to create topic cache directory: %v", err)
+		return
+	}
+	if err := os.WriteFile(cachePath, data, 0600); err != nil { //nosec G306
+		log.Printf("Warning: failed to cache posts for topic %d: %v", topicID, err)
+	}
+}
+
+// CachedTopicPosts returns the posts saved by the last full GetTopicPosts of
+// this topic, or an error if there are none.
+func (c *Client) CachedTopicPosts(topicID int) (*TopicResponse, error) {
+	cachePath, err := c.topicCachePath(topicID)
+	if err != nil {
+		return nil, err
+	}
+	// #nosec G304
+	data, err := os.ReadFile(cachePath)
+	if err != nil {
+		return nil, err
+	}
+	var posts TopicResponse
+	if err := json.Unmarshal(data, &posts); err != nil {
+		return nil, fmt.Errorf("failed to parse cached posts for topic %d: %w", topicID, err)
+	}
+	return &posts, nil
+}
+
+// PrefetchTopicPosts warms the disk cache with the posts of the given topics,
+// skipping any whose cache is newer than their last post. It waits the page
+// cooldown between topics, stops as soon as ctx is cancelled and returns how
+// many topics were fetched.
+func (c *Client) PrefetchTopicPosts(ctx context.Context, topics []Topic) int {
+	fetched := 0
+	for _, topic := range topics {
+		if ctx.Err() != nil {
+			return fetched
+		}
+		if c.RateLimitedFor() > 0 {
+			log.Printf("Stopping prefetch while rate limited")
+			return fetched
+		}
+		if cachePath, err := c.topicCachePath(topic.ID); err == nil {
+			if info, err := os.Stat(cachePath); err == nil && info.ModTime().After(topic.LastPostedAt) {
+				continue
+			}
+		}
+
+		if c.waitCooldown(ctx) != nil {
+			return fetched
+		}
+
+		if _, err := c.GetTopicPostsContext(ctx, topic.ID); err != nil {
+			if ctx.Err() != nil {
+				return fetched
+			}
+			log.Printf("Warning: failed to prefetch topic %d: %v", topic.ID, err)
+			continue
+		}
+		fetched++
+	}
+	return fetched
+}
+
+// GetBookmarks returns the current user's bookmarks as a topic list. Post
+// bookmarks point at their topic, with LastReadPostNumber set to the post.
+func (c *Client) GetBookmarks() (*Response, error) {
+	user, err := c.GetCurrentUser()
+	if err != nil {
+		return nil, fmt.Errorf("bookmarks require login: %w", err)
+	}
+
+	req, err := http.NewRequest("GET", c.endpoint(fmt.Sprintf("/u/%s/bookmarks.json", url.PathEscape(user.Username))), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bookmarks request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.doRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch bookmarks: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("bookmarks API error: %s - %s", resp.Status, string(body))
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bookmarks response body: %w", err)
+	}
+	if !gjson.ValidBytes(body) {
+		return nil, fmt.Errorf("invalid JSON response from server")
+	}
+
+	result := gjson.ParseBytes(body)
+	response := &Response{}
+	response.TopicList.MoreTopicsURL = result.Get("user_bookmark_list.more_bookmarks_url").Str
+
+	bookmarks := result.Get("user_bookmark_list.bookmarks")
+	bookmarks.ForEach(func(_, value gjson.Result) bool {
+		topic := Topic{
+			ID:                 int(value.Get("topic_id").Int()),
+			Title:              value.Get("title").Str,
+			FancyTitle:         value.Get("fancy_title").Str,
+			Slug:               value.Get("slug").Str,
+			CreatedAt:          value.Get("created_at").Time(),
+			BumpedAt:           value.Get("bumped_at").Time(),
+			LastReadPostNumber: int(value.Get("linked_post_number").Int()),
+			CategoryID:         int(value.Get("category_id").Int()),
+			Bookmarked:         true,
+		}
+		value.Get("tags").ForEach(func(_, tag gjson.Result) bool {
+			topic.Tags = append(topic.Tags, tag.Str)
+			return true
+		})
+		response.TopicList.Topics = append(response.TopicList.Topics, topic)
+		return true
+	})
+	c.EnrichTopicCategories(response.TopicList.Topics)
+
+	return response, nil
 }
@@ -0,0 +1,556 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package discourse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewClientWithHTTPClient(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		want    string
+		wantErr bool
+	}{
+		{name: "bare host", baseURL: "forum.example.com", want: "https://forum.example.com"},
+		{name: "trailing slash", baseURL: "https://forum.example.com/", want: "https://forum.example.com"},
+		{name: "http kept", baseURL: "http://localhost:3000", want: "http://localhost:3000"},
+		{name: "surrounding space", baseURL: "  forum.example.com ", want: "https://forum.example.com"},
+		{name: "empty", baseURL: "", wantErr: true},
+		{name: "query", baseURL: "forum.example.com/?x=1", wantErr: true},
+		{name: "bad host", baseURL: "forum example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClientWithHTTPClient(tt.baseURL, "", false, &http.Client{})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("NewClientWithHTTPClient(%q) = %q, want an error", tt.baseURL, c.BaseURL())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewClientWithHTTPClient(%q): %v", tt.baseURL, err)
+			}
+			if got := c.BaseURL(); got != tt.want {
+				t.Errorf("BaseURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewClientWithHTTPClientNil(t *testing.T) {
+	if _, err := NewClientWithHTTPClient("forum.example.com", "", false, nil); err == nil {
+		t.Fatal("NewClientWithHTTPClient with a nil http.Client succeeded, want an error")
+	}
+}
+
+func TestNewClientWithHTTPClientUsesClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/site/basic-info.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"title":"Test forum"}`))
+	}))
+	defer server.Close()
+
+	httpClient := server.Client()
+	c, err := NewClientWithHTTPClient(server.URL, "", false, httpClient)
+	if err != nil {
+		t.Fatalf("NewClientWithHTTPClient: %v", err)
+	}
+	if httpClient.Jar == nil {
+		t.Error("no cookie jar was attached to the http.Client")
+	}
+	if err := c.Ping(); err != nil {
+		t.Errorf("Ping through the injected client: %v", err)
+	}
+}
+
+// fixtureClient returns a client for a test server answering each path in
+// routes with that file from testdata, or with a 500 for an empty name. A
+// route may include the query to only answer requests with it. The client's
+// cache is kept in a temporary directory.
+func fixtureClient(t *testing.T, routes map[string]string) *Client {
+	t.Helper()
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	t.Setenv("HOME", cacheDir)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := routes[r.URL.RequestURI()]
+		if !ok {
+			name, ok = routes[r.URL.Path]
+		}
+		if !ok {
+			t.Errorf("unexpected request for %s", r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		if name == "" {
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		data, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Errorf("reading fixture: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	t.Cleanup(server.Close)
+
+	c, err := NewClientWithHTTPClient(server.URL, "", false, server.Client())
+	if err != nil {
+		t.Fatalf("NewClientWithHTTPClient: %v", err)
+	}
+	c.SetPageCooldown(0)
+	return c
+}
+
+func TestGetLatestTopics(t *testing.T) {
+	tests := []struct {
+		name       string
+		routes     map[string]string
+		titles     []string
+		categories []string
+		creators   []string
+		moreURL    string
+		wantErr    bool
+	}{
+		{
+			name:       "fixture",
+			routes:     map[string]string{"/latest.json": "latest.json", "/categories.json": "categories.json"},
+			titles:     []string{"Welcome to the forum", "Release notes"},
+			categories: []string{"General", "Announcements"},
+			creators:   []string{"alice", "bob"},
+			moreURL:    "/latest?no_definitions=true&page=1",
+		},
+		{
+			name:       "categories unavailable",
+			routes:     map[string]string{"/latest.json": "latest.json", "/categories.json": ""},
+			titles:     []string{"Welcome to the forum", "Release notes"},
+			categories: []string{"", ""},
+			creators:   []string{"alice", "bob"},
+			moreURL:    "/latest?no_definitions=true&page=1",
+		},
+		{name: "server error", routes: map[string]string{"/latest.json": ""}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fixtureClient(t, tt.routes)
+			got, err := c.GetLatestTopics()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("GetLatestTopics succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetLatestTopics: %v", err)
+			}
+			var titles, categories, creators []string
+			for _, topic := range got.TopicList.Topics {
+				titles = append(titles, topic.Title)
+				categories = append(categories, topic.CategoryName)
+				creators = append(creators, topic.CreatorUsername)
+			}
+			if !reflect.DeepEqual(titles, tt.titles) {
+				t.Errorf("titles = %q, want %q", titles, tt.titles)
+			}
+			if !reflect.DeepEqual(categories, tt.categories) {
+				t.Errorf("categories = %q, want %q", categories, tt.categories)
+			}
+			if !reflect.DeepEqual(creators, tt.creators) {
+				t.Errorf("creators = %q, want %q", creators, tt.creators)
+			}
+			if got.TopicList.MoreTopicsURL != tt.moreURL {
+				t.Errorf("MoreTopicsURL = %q, want %q", got.TopicList.MoreTopicsURL, tt.moreURL)
+			}
+			dir, err := InstanceCacheDir(c.BaseURL())
+			if err != nil {
+				t.Fatalf("InstanceCacheDir: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, "latest.json")); err != nil {
+				t.Errorf("latest topics weren't cached: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetCategories(t *testing.T) {
+	tests := []struct {
+		name         string
+		routes       map[string]string
+		names        []string
+		requiredTags []int
+		wantErr      bool
+	}{
+		{
+			name:         "fixture",
+			routes:       map[string]string{"/categories.json": "categories.json"},
+			names:        []string{"General", "Announcements"},
+			requiredTags: []int{0, 1},
+		},
+		{name: "server error", routes: map[string]string{"/categories.json": ""}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fixtureClient(t, tt.routes)
+			got, err := c.GetCategories()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("GetCategories succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetCategories: %v", err)
+			}
+			var names []string
+			var requiredTags []int
+			for _, category := range got.CategoryList.Categories {
+				names = append(names, category.Name)
+				requiredTags = append(requiredTags, category.MinimumRequiredTags)
+			}
+			if !reflect.DeepEqual(names, tt.names) {
+				t.Errorf("names = %q, want %q", names, tt.names)
+			}
+			if !reflect.DeepEqual(requiredTags, tt.requiredTags) {
+				t.Errorf("minimum required tags = %v, want %v", requiredTags, tt.requiredTags)
+			}
+			if !got.CategoryList.CanCreateTopic {
+				t.Error("CanCreateTopic = false, want true")
+			}
+
+			// The second call is answered from the cache
+			tt.routes["/categories.json"] = ""
+			cached, err := c.GetCategories()
+			if err != nil {
+				t.Fatalf("GetCategories from the cache: %v", err)
+			}
+			if len(cached.CategoryList.Categories) != len(tt.names) {
+				t.Errorf("cached categories = %d, want %d", len(cached.CategoryList.Categories), len(tt.names))
+			}
+		})
+	}
+}
+
+func TestGetTopicPosts(t *testing.T) {
+	tests := []struct {
+		name      string
+		routes    map[string]string
+		numbers   []int
+		usernames []string
+		wantErr   bool
+	}{
+		{
+			name:      "inline and fetched posts",
+			routes:    map[string]string{"/t/7.json": "topic_7.json", "/t/7/posts.json": "topic_7_posts.json", "/categories.json": "categories.json"},
+			numbers:   []int{1, 2, 3},
+			usernames: []string{"alice", "bob", "bob"},
+		},
+		{
+			name:    "rest of the stream fails",
+			routes:  map[string]string{"/t/7.json": "topic_7.json", "/t/7/posts.json": "", "/categories.json": "categories.json"},
+			wantErr: true,
+		},
+		{name: "topic fails", routes: map[string]string{"/t/7.json": ""}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fixtureClient(t, tt.routes)
+			got, err := c.GetTopicPosts(7)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("GetTopicPosts succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetTopicPosts: %v", err)
+			}
+			var numbers []int
+			var usernames []string
+			for _, post := range got.PostStream.Posts {
+				numbers = append(numbers, post.PostNumber)
+				usernames = append(usernames, post.Username)
+			}
+			if !reflect.DeepEqual(numbers, tt.numbers) {
+				t.Errorf("post numbers = %v, want %v", numbers, tt.numbers)
+			}
+			if !reflect.DeepEqual(usernames, tt.usernames) {
+				t.Errorf("usernames = %q, want %q", usernames, tt.usernames)
+			}
+			if want := []int{101, 102, 103}; !reflect.DeepEqual(got.PostStream.Stream, want) {
+				t.Errorf("stream = %v, want %v", got.PostStream.Stream, want)
+			}
+		})
+	}
+}
+
+func TestGetMoreTopicsInCategory(t *testing.T) {
+	tests := []struct {
+		name    string
+		moreURL string
+		titles  []string
+		nextURL string
+		wantErr bool
+	}{
+		{
+			name:    "second page",
+			moreURL: "/c/general/4/l/latest?page=1",
+			titles:  []string{"Favourite terminal fonts"},
+			nextURL: "/c/general/4/l/latest?page=2",
+		},
+		{name: "missing page", moreURL: "/c/general/4/l/latest?page=9", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := fixtureClient(t, map[string]string{
+				"/c/general/4/l/latest.json?page=1": "category_general_page1.json",
+				"/c/general/4/l/latest.json?page=9": "",
+				"/categories.json":                  "categories.json",
+			})
+			got, err := c.GetMoreTopics(tt.moreURL)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("GetMoreTopics succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetMoreTopics(%q): %v", tt.moreURL, err)
+			}
+			var titles []string
+			for _, topic := range got.TopicList.Topics {
+				titles = append(titles, topic.Title)
+				if topic.CategoryName != "General" {
+					t.Errorf("topic %d category = %q, want General", topic.ID, topic.CategoryName)
+				}
+			}
+			if !reflect.DeepEqual(titles, tt.titles) {
+				t.Errorf("titles = %q, want %q", titles, tt.titles)
+			}
+			// Paging on must stay within the category
+			if got.TopicList.MoreTopicsURL != tt.nextURL {
+				t.Errorf("MoreTopicsURL = %q, want %q", got.TopicList.MoreTopicsURL, tt.nextURL)
+			}
+		})
+	}
+}
+
+func TestValidateBaseURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		baseURL string
+		wantErr bool
+	}{
+		{name: "plain", baseURL: "https://forum.example.com"},
+		{name: "port", baseURL: "http://localhost:3000"},
+		{name: "ipv6", baseURL: "http://[::1]:3000"},
+		{name: "internationalised", baseURL: "https://forum.bücher.example"},
+		{name: "punycode", baseURL: "https://forum.xn--bcher-kva.example"},
+		{name: "no host", baseURL: "https://", wantErr: true},
+		{name: "space in host", baseURL: "https://forum example.com", wantErr: true},
+		{name: "bad character", baseURL: "https://forum!.example.com", wantErr: true},
+		{name: "query", baseURL: "https://forum.example.com?page=1", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateBaseURL(tt.baseURL); (err != nil) != tt.wantErr {
+				t.Errorf("validateBaseURL(%q) = %v, want error %t", tt.baseURL, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTopicListURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		moreURL string
+		want    string
+	}{
+		{name: "latest", moreURL: "/latest?page=1", want: "https://forum.example.com/latest.json?page=1"},
+		{name: "category", moreURL: "/c/general/4/l/latest?page=2", want: "https://forum.example.com/c/general/4/l/latest.json?page=2"},
+		{name: "already json", moreURL: "/top.json?period=weekly&page=1", want: "https://forum.example.com/top.json?period=weekly&page=1"},
+		{name: "no leading slash", moreURL: "latest?page=1", want: "https://forum.example.com/latest.json?page=1"},
+		{name: "trailing slash", moreURL: "/latest/?page=1", want: "https://forum.example.com/latest.json?page=1"},
+		{name: "absolute", moreURL: "https://other.example.com/latest?page=1", want: "https://other.example.com/latest.json?page=1"},
+		{name: "prefix added", prefix: "/forum", moreURL: "/latest?page=1", want: "https://forum.example.com/forum/latest.json?page=1"},
+		{name: "prefix kept", prefix: "/forum", moreURL: "/forum/latest?page=1", want: "https://forum.example.com/forum/latest.json?page=1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := NewClientWithHTTPClient("forum.example.com", "", false, &http.Client{})
+			if err != nil {
+				t.Fatalf("NewClientWithHTTPClient: %v", err)
+			}
+			c.SetPathPrefix(tt.prefix)
+			got, err := c.topicListURL(tt.moreURL)
+			if err != nil {
+				t.Fatalf("topicListURL(%q): %v", tt.moreURL, err)
+			}
+			if got != tt.want {
+				t.Errorf("topicListURL(%q) = %q, want %q", tt.moreURL, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveCookiesRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cookies.txt")
+	base, err := url.Parse("https://forum.example.com/forum")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewClientWithHTTPClient(base.String(), path, false, &http.Client{})
+	if err != nil {
+		t.Fatalf("NewClientWithHTTPClient: %v", err)
+	}
+	// The server cleared _t on /forum and the theme was set on both paths,
+	// so the jar holds two cookies of each name
+	client.client.Jar.SetCookies(base, []*http.Cookie{
+		{Name: "_t", Value: "", Path: "/forum"},
+		{Name: "theme", Value: "dark", Path: "/forum"},
+	})
+	client.client.Jar.SetCookies(base, []*http.Cookie{
+		{Name: "_t", Value: "token", Path: "/"},
+		{Name: "theme", Value: "light", Path: "/"},
+	})
+	if err := client.SaveCookies(path); err != nil {
+		t.Fatalf("SaveCookies: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "_t=token\ntheme=dark"; got != want {
+		t.Errorf("saved file = %q, want %q", got, want)
+	}
+
+	loaded, err := NewClientWithHTTPClient(base.String(), path, false, &http.Client{})
+	if err != nil {
+		t.Fatalf("NewClientWithHTTPClient: %v", err)
+	}
+	if err := loaded.LoadCookies(path); err != nil {
+		t.Fatalf("LoadCookies: %v", err)
+	}
+	got := make(map[string]string)
+	for _, cookie := range loaded.client.Jar.Cookies(base) {
+		got[cookie.Name] = cookie.Value
+	}
+	if want := map[string]string{"_t": "token", "theme": "dark"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded cookies = %v, want %v", got, want)
+	}
+}
+
+func TestDedupeCookies(t *testing.T) {
+	cookie := func(name, value, path string) *http.Cookie {
+		return &http.Cookie{Name: name, Value: value, Path: path}
+	}
+	tests := []struct {
+		name    string
+		cookies []*http.Cookie
+		want    []*http.Cookie
+	}{
+		{
+			name:    "distinct names",
+			cookies: []*http.Cookie{cookie("_t", "a", "/"), cookie("theme", "dark", "/")},
+			want:    []*http.Cookie{cookie("_t", "a", "/"), cookie("theme", "dark", "/")},
+		},
+		{
+			name:    "first of a name wins",
+			cookies: []*http.Cookie{cookie("theme", "dark", "/forum"), cookie("theme", "light", "/")},
+			want:    []*http.Cookie{cookie("theme", "dark", "/forum")},
+		},
+		{
+			name:    "cleared session cookie replaced",
+			cookies: []*http.Cookie{cookie("_t", "", "/forum"), cookie("_t", "token", "/")},
+			want:    []*http.Cookie{cookie("_t", "token", "/")},
+		},
+		{
+			name:    "real session cookie kept",
+			cookies: []*http.Cookie{cookie("_forum_session", "s1", "/forum"), cookie("_forum_session", "", "/")},
+			want:    []*http.Cookie{cookie("_forum_session", "s1", "/forum")},
+		},
+		{
+			name:    "cleared other cookie not replaced",
+			cookies: []*http.Cookie{cookie("theme", "", "/forum"), cookie("theme", "dark", "/")},
+			want:    []*http.Cookie{cookie("theme", "", "/forum")},
+		},
+		{name: "none", cookies: nil, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := dedupeCookies(tt.cookies); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("dedupeCookies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergePostsByStream(t *testing.T) {
+	post := func(id int, cooked string) Post {
+		return Post{ID: id, Cooked: cooked}
+	}
+	tests := []struct {
+		name    string
+		stream  []int
+		sources [][]Post
+		want    []Post
+	}{
+		{
+			name:    "ordered by stream",
+			stream:  []int{1, 2, 3},
+			sources: [][]Post{{post(3, ""), post(1, "")}, {post(2, "")}},
+			want:    []Post{post(1, ""), post(2, ""), post(3, "")},
+		},
+		{
+			name:    "first source wins",
+			stream:  []int{1},
+			sources: [][]Post{{post(1, "inline")}, {post(1, "fetched")}},
+			want:    []Post{post(1, "inline")},
+		},
+		{
+			name:    "deleted posts skipped",
+			stream:  []int{1, 2, 3},
+			sources: [][]Post{{post(1, ""), post(3, "")}},
+			want:    []Post{post(1, ""), post(3, "")},
+		},
+		{
+			name:    "posts outside the stream dropped",
+			stream:  []int{2},
+			sources: [][]Post{{post(1, ""), post(2, "")}},
+			want:    []Post{post(2, "")},
+		},
+		{
+			name:    "repeated stream ID listed once",
+			stream:  []int{1, 1},
+			sources: [][]Post{{post(1, "")}},
+			want:    []Post{post(1, "")},
+		},
+		{name: "empty", want: []Post{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mergePostsByStream(tt.stream, tt.sources...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("mergePostsByStream() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package discourse
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// ErrorMessage turns common network failures into guidance a user can act on
+// and returns any other error's text unchanged. The client wraps errors with
+// %w, so the cause is found with errors.Is and errors.As.
+func ErrorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	if errors.Is(err, ErrRateLimited) {
+		return "the forum is rate limiting requests — wait a little and try again"
+	}
+	if hint := networkHint(err); hint != "" {
+		return hint
+	}
+	return err.Error()
+}
+
+// IsNetworkError reports whether err means the forum couldn't be reached at
+// all, as opposed to an error response from it.
+func IsNetworkError(err error) bool {
+	return err != nil && !errors.Is(err, ErrSSORequired) && networkHint(err) != ""
+}
+
+func networkHint(err error) string {
+	if errors.Is(err, ErrSSORequired) {
+		return ErrSSORequired.Error()
+	}
+
+	var dnsErr *net.DNSError
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certInvalid x509.CertificateInvalidError
+	var verifyErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return "couldn't resolve the hostname — check the URL"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused — is the forum online?"
+	case errors.Is(err, syscall.ECONNRESET):
+		return "the connection was reset — check your network or try again"
+	case errors.As(err, &unknownAuthority), errors.As(err, &hostnameErr), errors.As(err, &certInvalid), errors.As(err, &verifyErr):
+		return "the forum's TLS certificate isn't trusted — check the URL or your system's certificates"
+	case errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return "TLS handshake failed — " + err.Error()
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "the forum took too long to respond — check your connection or try again"
+	case errors.Is(err, syscall.ENETUNREACH):
+		return "network is unreachable — are you online?"
+	}
+	return ""
+}
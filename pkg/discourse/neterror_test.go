@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package discourse
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"syscall"
+	"testing"
+)
+
+func TestErrorMessage(t *testing.T) {
+	wrap := func(err error) error {
+		return fmt.Errorf("failed to fetch latest topics: %w", &url.Error{Op: "Get", URL: "https://forum.example.com", Err: err})
+	}
+	tests := []struct {
+		name    string
+		err     error
+		want    string
+		network bool
+	}{
+		{name: "dns", err: wrap(&net.DNSError{Err: "no such host", Name: "forum.example.com"}), want: "couldn't resolve the hostname — check the URL", network: true},
+		{name: "refused", err: wrap(&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}), want: "connection refused — is the forum online?", network: true},
+		{name: "timeout", err: wrap(context.DeadlineExceeded), want: "the forum took too long to respond — check your connection or try again", network: true},
+		{name: "rate limited", err: fmt.Errorf("failed to fetch latest topics: %w", ErrRateLimited), want: "the forum is rate limiting requests — wait a little and try again"},
+		{name: "sso", err: fmt.Errorf("login failed: %w", ErrSSORequired), want: ErrSSORequired.Error()},
+		{name: "other", err: errors.New("unexpected status 500"), want: "unexpected status 500"},
+		{name: "message only", err: errors.New("dial tcp: connection refused"), want: "dial tcp: connection refused"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ErrorMessage(tt.err); got != tt.want {
+				t.Errorf("ErrorMessage() = %q, want %q", got, tt.want)
+			}
+			if got := IsNetworkError(tt.err); got != tt.network {
+				t.Errorf("IsNetworkError() = %t, want %t", got, tt.network)
+			}
+		})
+	}
+}
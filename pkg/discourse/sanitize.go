@@ -0,0 +1,43 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package discourse
+
+import (
+	"strings"
+	"unicode"
+)
+
+const tabWidth = 4
+
+// SanitizeRaw normalizes post content before it is sent: line endings become
+// \n, tabs are expanded to the next 4-column stop and other control
+// characters (which Discourse may reject, or which render oddly) are dropped.
+// It returns the cleaned text and how many characters were dropped.
+func SanitizeRaw(raw string) (string, int) {
+	raw = strings.ToValidUTF8(raw, "")
+	raw = strings.ReplaceAll(raw, "\r\n", "\n")
+	raw = strings.ReplaceAll(raw, "\r", "\n")
+
+	var b strings.Builder
+	b.Grow(len(raw))
+	removed := 0
+	column := 0
+	for _, r := range raw {
+		switch {
+		case r == '\n':
+			b.WriteRune(r)
+			column = 0
+		case r == '\t':
+			spaces := tabWidth - column%tabWidth
+			b.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+		case unicode.IsControl(r):
+			removed++
+		default:
+			b.WriteRune(r)
+			column++
+		}
+	}
+	return b.String(), removed
+}
@@ -0,0 +1,32 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package discourse
+
+import "testing"
+
+func TestSanitizeRaw(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		want        string
+		wantRemoved int
+	}{
+		{name: "plain", raw: "hello world", want: "hello world"},
+		{name: "crlf", raw: "one\r\ntwo\rthree", want: "one\ntwo\nthree"},
+		{name: "leading tab", raw: "\tcode", want: "    code"},
+		{name: "tab to next stop", raw: "ab\tc", want: "ab  c"},
+		{name: "tab column resets per line", raw: "abc\n\tx", want: "abc\n    x"},
+		{name: "control characters", raw: "a\x00b\x1bc\x7f", want: "abc", wantRemoved: 3},
+		{name: "invalid utf-8", raw: "a\xffb", want: "ab"},
+		{name: "unicode kept", raw: "héllo ✓", want: "héllo ✓"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, removed := SanitizeRaw(tt.raw)
+			if got != tt.want || removed != tt.wantRemoved {
+				t.Errorf("SanitizeRaw(%q) = %q, %d; want %q, %d", tt.raw, got, removed, tt.want, tt.wantRemoved)
+			}
+		})
+	}
+}
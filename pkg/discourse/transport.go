@@ -0,0 +1,105 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package discourse
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Idle connection defaults of the built-in transport. The client talks to a
+// single forum, so a few connections are kept warm for it between the
+// requests of a load-all or prefetch rather than spread over many hosts.
+const (
+	DefaultMaxIdleConns        = 10
+	DefaultMaxIdleConnsPerHost = 4
+	DefaultIdleConnTimeout     = 90 * time.Second
+)
+
+// tlsTransport wraps the client's http.Transport so that TLS handshake
+// failures caused by the minimum version read as such instead of as a bare
+// handshake error.
+type tlsTransport struct {
+	base *http.Transport
+}
+
+func newTransport() *tlsTransport {
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		VerifyConnection: func(cs tls.ConnectionState) error {
+			log.Printf("TLS connection to %s: %s, %s", cs.ServerName, tls.VersionName(cs.Version), tls.CipherSuiteName(cs.CipherSuite))
+			return nil
+		},
+	}
+	base.MaxIdleConns = DefaultMaxIdleConns
+	base.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	base.IdleConnTimeout = DefaultIdleConnTimeout
+	return &tlsTransport{base: base}
+}
+
+func (t *tlsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil && isTLSVersionError(err) {
+		return nil, fmt.Errorf("%s does not support %s or newer (see --min-tls): %w", req.URL.Host, tls.VersionName(t.base.TLSClientConfig.MinVersion), err)
+	}
+	return resp, err
+}
+
+func isTLSVersionError(err error) bool {
+	var alert tls.AlertError
+	if errors.As(err, &alert) && alert == 70 { // protocol_version
+		return true
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "protocol version not supported") || strings.Contains(msg, "unsupported protocol version")
+}
+
+// ParseTLSVersion converts "1.0" through "1.3" into the crypto/tls constant.
+func ParseTLSVersion(version string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(version), "tls") {
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unknown TLS version %q (expected 1.0, 1.1, 1.2 or 1.3)", version)
+}
+
+// SetMinTLSVersion sets the oldest TLS version the client will negotiate. It
+// only applies to clients using the built-in transport.
+func (c *Client) SetMinTLSVersion(version uint16) error {
+	if c.transport == nil {
+		return fmt.Errorf("TLS settings are not managed for a custom http client")
+	}
+	c.transport.TLSClientConfig.MinVersion = version
+	c.transport.CloseIdleConnections()
+	return nil
+}
+
+// SetIdleConns sets how many idle connections the built-in transport keeps
+// open in total and per host, and how long an idle one is kept. A timeout of
+// 0 keeps idle connections until the server closes them.
+func (c *Client) SetIdleConns(maxIdle, maxIdlePerHost int, idleTimeout time.Duration) error {
+	if c.transport == nil {
+		return fmt.Errorf("connection settings are not managed for a custom http client")
+	}
+	if maxIdle < 0 || maxIdlePerHost < 0 || idleTimeout < 0 {
+		return fmt.Errorf("idle connection settings must not be negative")
+	}
+	c.transport.MaxIdleConns = maxIdle
+	c.transport.MaxIdleConnsPerHost = maxIdlePerHost
+	c.transport.IdleConnTimeout = idleTimeout
+	c.transport.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,159 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+// Package markdown turns the cooked HTML of Discourse posts back into
+// Markdown, for reading in the terminal and for exports.
+package markdown
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// LinkFunc writes a link with the given text and target.
+type LinkFunc func(text, href string) string
+
+// Link writes a link as Markdown, or as the bare URL when that is also its
+// text.
+func Link(text, href string) string {
+	if text == href || strings.TrimSpace(text) == "" {
+		return href
+	}
+	return fmt.Sprintf("[%s](%s)", text, href)
+}
+
+// HrefFromTag returns the link target of an <a href="..."> tag's contents,
+// or "" for any other tag.
+func HrefFromTag(tag string) string {
+	if !strings.HasPrefix(tag, "a ") {
+		return ""
+	}
+	return attrFromTag(tag, "href")
+}
+
+// attrFromTag returns the value of a double-quoted attribute of a tag's
+// contents, or "" if it is missing.
+func attrFromTag(tag, name string) string {
+	start := strings.Index(tag, " "+name+`="`)
+	if start < 0 {
+		return ""
+	}
+	start += len(name) + 3
+	end := strings.Index(tag[start:], `"`)
+	if end <= 0 {
+		return ""
+	}
+	return tag[start : start+end]
+}
+
+// imagePlaceholder stands in for an <img> tag: its alt text, or the file
+// name when there is none. Emoji are written as their :code:.
+func imagePlaceholder(tag string) string {
+	alt := attrFromTag(tag, "alt")
+	if strings.Contains(attrFromTag(tag, "class"), "emoji") && alt != "" {
+		return alt
+	}
+	if alt == "" {
+		src := attrFromTag(tag, "src")
+		if i := strings.IndexAny(src, "?#"); i >= 0 {
+			src = src[:i]
+		}
+		if src == "" {
+			return "[image]"
+		}
+		alt = path.Base(src)
+	}
+	return "[image: " + alt + "]"
+}
+
+// FromHTML converts the cooked HTML of a post back into Markdown: paragraphs,
+// code, quotes and emphasis keep their Markdown form, links are written by
+// link and images become "[image: alt]" placeholders.
+func FromHTML(html string, link LinkFunc) string {
+	html = strings.ReplaceAll(html, "<br/>", "\n")
+	html = strings.ReplaceAll(html, "<br>", "\n")
+	html = strings.ReplaceAll(html, "</p>", "\n\n")
+	html = strings.ReplaceAll(html, "</div>", "\n")
+	html = strings.ReplaceAll(html, "</blockquote>", "\n")
+
+	var result strings.Builder
+	var currentTag strings.Builder
+	var inTag bool
+	var inAnchor bool
+	var inPre bool
+	var anchorHref string
+	var anchorText strings.Builder
+	// anchorImage is the placeholder of an image inside the link, which
+	// replaces the link text: in a lightbox, that is the file's size info.
+	var anchorImage string
+
+	i := 0
+	for i < len(html) {
+		char := html[i]
+
+		if char == '<' {
+			inTag = true
+			currentTag.Reset()
+		} else if char == '>' && inTag {
+			inTag = false
+			tag := currentTag.String()
+
+			if href := HrefFromTag(tag); href != "" {
+				inAnchor = true
+				anchorText.Reset()
+				anchorImage = ""
+				anchorHref = href
+			} else if tag == "img" || strings.HasPrefix(tag, "img ") {
+				if inAnchor {
+					anchorImage += imagePlaceholder(tag)
+				} else {
+					result.WriteString(imagePlaceholder(tag))
+				}
+			} else if tag == "/a" && inAnchor {
+				inAnchor = false
+				text := anchorText.String()
+				if anchorImage != "" {
+					text = anchorImage
+				}
+				result.WriteString(link(text, anchorHref))
+				anchorHref = ""
+			} else if (tag == "code" || tag == "/code") && !inPre {
+				result.WriteString("`")
+			} else if tag == "pre" {
+				inPre = true
+				result.WriteString("\n```\n")
+			} else if tag == "/pre" {
+				inPre = false
+				result.WriteString("\n```\n")
+			} else if tag == "blockquote" {
+				result.WriteString("\n> ")
+			} else if tag == "strong" || tag == "b" {
+				result.WriteString("**")
+			} else if tag == "/strong" || tag == "/b" {
+				result.WriteString("**")
+			} else if tag == "em" || tag == "i" {
+				result.WriteString("*")
+			} else if tag == "/em" || tag == "/i" {
+				result.WriteString("*")
+			}
+		} else if inTag {
+			currentTag.WriteByte(char)
+		} else if inAnchor {
+			anchorText.WriteByte(char)
+		} else {
+			result.WriteByte(char)
+		}
+
+		i++
+	}
+
+	text := result.String()
+	text = strings.ReplaceAll(text, "&lt;", "<")
+	text = strings.ReplaceAll(text, "&gt;", ">")
+	text = strings.ReplaceAll(text, "&amp;", "&")
+	text = strings.ReplaceAll(text, "&quot;", "\"")
+	text = strings.ReplaceAll(text, "&#39;", "'")
+
+	return text
+}
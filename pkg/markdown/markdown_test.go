@@ -0,0 +1,53 @@
+// Copyright (c) 2025 Sudo-Ivan
+// MIT License
+
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestImagePlaceholder(t *testing.T) {
+	tests := []struct {
+		name string
+		tag  string
+		want string
+	}{
+		{name: "alt text", tag: `img src="/uploads/cat.png" alt="A cat"`, want: "[image: A cat]"},
+		{name: "file name", tag: `img src="https://example.com/uploads/cat.png?v=2"`, want: "[image: cat.png]"},
+		{name: "nothing to go by", tag: `img`, want: "[image]"},
+		{name: "emoji", tag: `img src="/images/emoji/smile.png" class="emoji" alt=":smile:"`, want: ":smile:"},
+		{name: "emoji without alt", tag: `img src="/images/emoji/smile.png" class="emoji"`, want: "[image: smile.png]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := imagePlaceholder(tt.tag); got != tt.want {
+				t.Errorf("imagePlaceholder(%q) = %q, want %q", tt.tag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromHTMLImages(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{name: "inline image", html: `<p>Look: <img src="/uploads/cat.png" alt="A cat"></p>`, want: "Look: [image: A cat]"},
+		{
+			name: "lightbox",
+			html: `<p><a class="lightbox" href="https://example.com/uploads/cat.png"><img src="/uploads/cat_small.png" alt="cat"><span class="meta">cat.png 800×600 50 KB</span></a></p>`,
+			want: "[[image: cat]](https://example.com/uploads/cat.png)",
+		},
+		{name: "emoji", html: `<p>Hi <img src="/images/emoji/wave.png" class="emoji" alt=":wave:"></p>`, want: "Hi :wave:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.TrimSpace(FromHTML(tt.html, Link)); got != tt.want {
+				t.Errorf("FromHTML(%q) = %q, want %q", tt.html, got, tt.want)
+			}
+		})
+	}
+}
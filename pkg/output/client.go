@@ -4,19 +4,122 @@
 package output
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"time"
+
 	"git.quad4.io/discourse-tui-client/pkg/discourse"
 )
 
+// DefaultConcurrency is the number of topics whose posts are fetched at once.
+const DefaultConcurrency = 4
+
 var client *discourse.Client
 
+var concurrency = DefaultConcurrency
+
 func SetClient(c *discourse.Client) {
 	client = c
 }
 
+// SetConcurrency sets how many topics' posts are fetched at once.
+func SetConcurrency(n int) error {
+	if n < 1 {
+		return fmt.Errorf("concurrency must be at least 1, got %d", n)
+	}
+	concurrency = n
+	return nil
+}
+
 func getTopicPosts(topicID int) (*discourse.TopicResponse, error) {
 	if client == nil {
 		return nil, fmt.Errorf("client not set")
 	}
 	return client.GetTopicPosts(topicID)
 }
+
+// getFirstPosts fetches only the first page of a topic's posts, for formats
+// that need just the opening post.
+func getFirstPosts(topicID int) (*discourse.TopicResponse, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client not set")
+	}
+	return client.GetTopicPostsPage(topicID, 1)
+}
+
+// knownPosts wraps fetch to answer from known for topics already fetched.
+func knownPosts(known map[int]*discourse.TopicResponse, fetch func(topicID int) (*discourse.TopicResponse, error)) func(topicID int) (*discourse.TopicResponse, error) {
+	if len(known) == 0 {
+		return fetch
+	}
+	return func(topicID int) (*discourse.TopicResponse, error) {
+		if posts, ok := known[topicID]; ok {
+			return posts, nil
+		}
+		return fetch(topicID)
+	}
+}
+
+// fetchAllPosts fetches the posts of every topic with a pool of workers and
+// returns them in the order of topics. Each worker waits the client's page
+// cooldown between its topics so the forum isn't hit harder than by a
+// single sequential export per worker. Topics in known aren't fetched again.
+func fetchAllPosts(topics []discourse.Topic, known map[int]*discourse.TopicResponse) ([]*discourse.TopicResponse, error) {
+	return fetchPosts(topics, knownPosts(known, getTopicPosts))
+}
+
+// fetchPosts is fetchAllPosts with the fetch of one topic's posts given.
+func fetchPosts(topics []discourse.Topic, fetch func(topicID int) (*discourse.TopicResponse, error)) ([]*discourse.TopicResponse, error) {
+	if client == nil {
+		return nil, fmt.Errorf("client not set")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	results := make([]*discourse.TopicResponse, len(topics))
+	errs := make([]error, len(topics))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(topics)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			first := true
+			for i := range jobs {
+				if !first {
+					select {
+					case <-ctx.Done():
+						continue
+					case <-time.After(client.PageCooldown()):
+					}
+				}
+				first = false
+				posts, err := fetch(topics[i].ID)
+				if err != nil {
+					errs[i] = fmt.Errorf("failed to fetch posts for topic %d: %w", topics[i].ID, err)
+					cancel()
+					continue
+				}
+				results[i] = posts
+			}
+		}()
+	}
+
+	for i := range topics {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return results, nil
+}
@@ -4,13 +4,19 @@
 package output
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"git.quad4.io/discourse-tui-client/pkg/discourse"
+	"git.quad4.io/discourse-tui-client/pkg/markdown"
 )
 
 type Formatter interface {
@@ -23,11 +29,20 @@ func (f *JSONFormatter) Format(topics *discourse.Response) ([]byte, error) {
 	return json.MarshalIndent(topics, "", "  ")
 }
 
-type TextFormatter struct{}
+type TextFormatter struct {
+	// Posts holds posts already fetched, by topic ID, so they aren't
+	// fetched again.
+	Posts map[int]*discourse.TopicResponse
+}
 
 func (f *TextFormatter) Format(topics *discourse.Response) ([]byte, error) {
+	allPosts, err := fetchAllPosts(topics.TopicList.Topics, f.Posts)
+	if err != nil {
+		return nil, err
+	}
+
 	var content strings.Builder
-	for _, topic := range topics.TopicList.Topics {
+	for i, topic := range topics.TopicList.Topics {
 		content.WriteString(fmt.Sprintf("Topic: %s\n", topic.Title))
 		if topic.CategoryName != "" {
 			content.WriteString(fmt.Sprintf("Category: %s\n", topic.CategoryName))
@@ -36,14 +51,11 @@ func (f *TextFormatter) Format(topics *discourse.Response) ([]byte, error) {
 			content.WriteString(fmt.Sprintf("Tags: %s\n", strings.Join(topic.Tags, ", ")))
 		}
 		content.WriteString(fmt.Sprintf("Created: %s\n", topic.CreatedAt.Format("2006-01-02 15:04:05")))
-		content.WriteString(fmt.Sprintf("Replies: %d\n", topic.ReplyCount))
+		content.WriteString(fmt.Sprintf("Replies: %d\n", topic.Replies()))
 		content.WriteString(fmt.Sprintf("Views: %d\n", topic.Views))
 		content.WriteString("\nPosts:\n")
 
-		posts, err := getTopicPosts(topic.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch posts for topic %d: %w", topic.ID, err)
-		}
+		posts := allPosts[i]
 
 		for _, post := range posts.PostStream.Posts {
 			content.WriteString(fmt.Sprintf("\nPost #%d by %s (%s)\n", post.PostNumber, post.Name, post.Username))
@@ -57,9 +69,197 @@ func (f *TextFormatter) Format(topics *discourse.Response) ([]byte, error) {
 	return []byte(content.String()), nil
 }
 
-type HTMLFormatter struct{}
+// MarkdownFormatter writes topics and their posts as Markdown, for note
+// taking apps and archives.
+type MarkdownFormatter struct {
+	// Posts holds posts already fetched, by topic ID, so they aren't
+	// fetched again.
+	Posts map[int]*discourse.TopicResponse
+}
+
+func (f *MarkdownFormatter) Format(topics *discourse.Response) ([]byte, error) {
+	allPosts, err := fetchAllPosts(topics.TopicList.Topics, f.Posts)
+	if err != nil {
+		return nil, err
+	}
+
+	var content strings.Builder
+	for i, topic := range topics.TopicList.Topics {
+		content.WriteString(fmt.Sprintf("# %s\n\n", topic.Title))
+		if topic.CategoryName != "" {
+			content.WriteString(fmt.Sprintf("- Category: %s\n", topic.CategoryName))
+		}
+		if len(topic.Tags) > 0 {
+			content.WriteString(fmt.Sprintf("- Tags: %s\n", strings.Join(topic.Tags, ", ")))
+		}
+		content.WriteString(fmt.Sprintf("- Created: %s\n", topic.CreatedAt.Format("2006-01-02 15:04:05")))
+		content.WriteString(fmt.Sprintf("- Replies: %d\n", topic.Replies()))
+		content.WriteString(fmt.Sprintf("- Views: %d\n", topic.Views))
+
+		posts := allPosts[i]
+
+		for _, post := range posts.PostStream.Posts {
+			content.WriteString(fmt.Sprintf("\n## Post #%d by %s\n\n", post.PostNumber, post.Username))
+			content.WriteString(fmt.Sprintf("*Posted %s*\n\n", post.CreatedAt.Format("2006-01-02 15:04:05")))
+			content.WriteString(strings.TrimSpace(markdown.FromHTML(post.Cooked, markdown.Link)))
+			content.WriteString("\n")
+		}
+		content.WriteString("\n---\n\n")
+	}
+	return []byte(content.String()), nil
+}
+
+// CSVFormatter writes one row per topic, without posts, for spreadsheets.
+type CSVFormatter struct{}
+
+func (f *CSVFormatter) Format(topics *discourse.Response) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"id", "title", "category", "tags", "reply_count", "views", "like_count", "created_at", "last_posted_at"}); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+	for _, topic := range topics.TopicList.Topics {
+		record := []string{
+			strconv.Itoa(topic.ID),
+			topic.Title,
+			topic.CategoryName,
+			strings.Join(topic.Tags, ", "),
+			strconv.Itoa(topic.Replies()),
+			strconv.Itoa(topic.Views),
+			strconv.Itoa(topic.LikeCount),
+			csvTime(topic.CreatedAt),
+			csvTime(topic.LastPostedAt),
+		}
+		if err := w.Write(record); err != nil {
+			return nil, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// csvTime leaves unknown times empty rather than writing year 1.
+func csvTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// AtomFormatter writes topics as an Atom feed, one entry per topic with its
+// first post as the summary, for feed readers.
+type AtomFormatter struct {
+	// Posts holds posts already fetched, by topic ID, so they aren't
+	// fetched again.
+	Posts map[int]*discourse.TopicResponse
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title    string       `xml:"title"`
+	ID       string       `xml:"id"`
+	Link     atomLink     `xml:"link"`
+	Updated  string       `xml:"updated"`
+	Author   *atomAuthor  `xml:"author,omitempty"`
+	Category []atomTerm   `xml:"category"`
+	Summary  *atomSummary `xml:"summary,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomTerm struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomSummary struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func (f *AtomFormatter) Format(topics *discourse.Response) ([]byte, error) {
+	firstPosts, err := fetchPosts(topics.TopicList.Topics, knownPosts(f.Posts, getFirstPosts))
+	if err != nil {
+		return nil, err
+	}
+
+	// The feed's author stands in for entries whose first post couldn't be
+	// fetched; Atom rejects entries without one
+	feed := atomFeed{
+		Title:  "Discourse Topics",
+		ID:     client.BaseURL() + "/latest",
+		Link:   atomLink{Href: client.BaseURL()},
+		Author: atomAuthor{Name: strings.TrimPrefix(strings.TrimPrefix(client.BaseURL(), "https://"), "http://")},
+	}
+	var newest time.Time
+	for i, topic := range topics.TopicList.Topics {
+		updated := topic.LastPostedAt
+		if updated.IsZero() {
+			updated = topic.CreatedAt
+		}
+		if updated.After(newest) {
+			newest = updated
+		}
+		link := client.TopicURL(topic)
+		entry := atomEntry{
+			Title:   topic.Title,
+			ID:      link,
+			Link:    atomLink{Href: link},
+			Updated: updated.UTC().Format(time.RFC3339),
+		}
+		if topic.CategoryName != "" {
+			entry.Category = append(entry.Category, atomTerm{Term: topic.CategoryName})
+		}
+		for _, tag := range topic.Tags {
+			entry.Category = append(entry.Category, atomTerm{Term: tag})
+		}
+		if posts := firstPosts[i].PostStream.Posts; len(posts) > 0 {
+			entry.Author = &atomAuthor{Name: posts[0].Username}
+			entry.Summary = &atomSummary{Type: "html", Body: posts[0].Cooked}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	if newest.IsZero() {
+		newest = time.Now()
+	}
+	feed.Updated = newest.UTC().Format(time.RFC3339)
+
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode feed: %w", err)
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+type HTMLFormatter struct {
+	// Posts holds posts already fetched, by topic ID, so they aren't
+	// fetched again.
+	Posts map[int]*discourse.TopicResponse
+}
 
 func (f *HTMLFormatter) Format(topics *discourse.Response) ([]byte, error) {
+	allPosts, err := fetchAllPosts(topics.TopicList.Topics, f.Posts)
+	if err != nil {
+		return nil, err
+	}
+
 	var content strings.Builder
 	content.WriteString(`<!DOCTYPE html>
 <html>
@@ -79,7 +279,7 @@ func (f *HTMLFormatter) Format(topics *discourse.Response) ([]byte, error) {
 <body>
 `)
 
-	for _, topic := range topics.TopicList.Topics {
+	for i, topic := range topics.TopicList.Topics {
 		content.WriteString(fmt.Sprintf(`<div class="topic">
     <h2>%s</h2>`, topic.Title))
 
@@ -93,12 +293,9 @@ func (f *HTMLFormatter) Format(topics *discourse.Response) ([]byte, error) {
     Created: %s<br>
     Replies: %d<br>
     Views: %d
-</div>`, topic.CreatedAt.Format("2006-01-02 15:04:05"), topic.ReplyCount, topic.Views))
+</div>`, topic.CreatedAt.Format("2006-01-02 15:04:05"), topic.Replies(), topic.Views))
 
-		posts, err := getTopicPosts(topic.ID)
-		if err != nil {
-			return nil, fmt.Errorf("failed to fetch posts for topic %d: %w", topic.ID, err)
-		}
+		posts := allPosts[i]
 
 		content.WriteString(`<div class="posts">`)
 		for _, post := range posts.PostStream.Posts {
@@ -120,21 +317,55 @@ func (f *HTMLFormatter) Format(topics *discourse.Response) ([]byte, error) {
 	return []byte(content.String()), nil
 }
 
-func WriteToFile(path string, topics *discourse.Response) error {
-	if !strings.HasSuffix(path, ".txt") && !strings.HasSuffix(path, ".json") && !strings.HasSuffix(path, ".html") {
-		return fmt.Errorf("output file must end with .txt, .json, or .html")
+// WriteTopicToFile writes a single topic, fetched by ID, in the format given
+// by the extension of path, as WriteToFile does for a topic list.
+func WriteTopicToFile(path string, topicID int) error {
+	if client == nil {
+		return fmt.Errorf("client not set")
+	}
+	if _, err := formatterFor(path, nil); err != nil {
+		return err
 	}
+	topic, posts, err := client.GetTopic(topicID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch topic %d: %w", topicID, err)
+	}
+	formatter, _ := formatterFor(path, map[int]*discourse.TopicResponse{topicID: posts})
 
-	var formatter Formatter
+	response := &discourse.Response{}
+	response.TopicList.Topics = []discourse.Topic{*topic}
+	return writeFormatted(path, formatter, response)
+}
+
+// formatterFor picks the formatter for the extension of path, handing it
+// the posts already fetched.
+func formatterFor(path string, posts map[int]*discourse.TopicResponse) (Formatter, error) {
 	switch {
 	case strings.HasSuffix(path, ".json"):
-		formatter = &JSONFormatter{}
+		return &JSONFormatter{}, nil
 	case strings.HasSuffix(path, ".html"):
-		formatter = &HTMLFormatter{}
-	default:
-		formatter = &TextFormatter{}
+		return &HTMLFormatter{Posts: posts}, nil
+	case strings.HasSuffix(path, ".md"):
+		return &MarkdownFormatter{Posts: posts}, nil
+	case strings.HasSuffix(path, ".csv"):
+		return &CSVFormatter{}, nil
+	case strings.HasSuffix(path, ".atom"):
+		return &AtomFormatter{Posts: posts}, nil
+	case strings.HasSuffix(path, ".txt"):
+		return &TextFormatter{Posts: posts}, nil
+	}
+	return nil, fmt.Errorf("output file must end with .txt, .json, .html, .md, .csv, or .atom")
+}
+
+func WriteToFile(path string, topics *discourse.Response) error {
+	formatter, err := formatterFor(path, nil)
+	if err != nil {
+		return err
 	}
+	return writeFormatted(path, formatter, topics)
+}
 
+func writeFormatted(path string, formatter Formatter, topics *discourse.Response) error {
 	data, err := formatter.Format(topics)
 	if err != nil {
 		return fmt.Errorf("failed to format output: %w", err)